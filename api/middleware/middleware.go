@@ -18,7 +18,7 @@ func CORS(next http.Handler) http.Handler {
 		}
 
 		// Métodos permitidos
-		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
 
 		// Headers permitidos
 		w.Header().Set("Access-Control-Allow-Headers",
@@ -64,7 +64,7 @@ func CORSForProduction(allowedOrigins []string) func(http.Handler) http.Handler
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
 
-			w.Header().Set("Access-Control-Allow-Methods", "GET")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
 			w.Header().Set("Access-Control-Allow-Headers",
 				"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 			w.Header().Set("Access-Control-Max-Age", "86400")
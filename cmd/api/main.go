@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"lottery-analyzer/api/middleware"
 	"lottery-analyzer/internal/config"
+	"lottery-analyzer/internal/migration"
+	"lottery-analyzer/internal/model"
 	"lottery-analyzer/internal/repository"
 	"lottery-analyzer/internal/service"
 	"lottery-analyzer/pkg/database"
@@ -22,25 +28,75 @@ import (
 
 type API struct {
 	processor service.ProcessorService
+	ledger    service.LedgerService
+	jobs      service.JobService
 }
 
+// processAnalysis atiende POST /api/v1/analysis/process: encola el análisis y devuelve el trabajo
+// enseguida; el resultado se consulta en GET /api/v1/jobs/{id}
 func (api *API) processAnalysis(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ctx := r.Context()
-	analysis, err := api.processor.ProcessAnalysis(ctx)
+	job, err := api.jobs.Submit(analysisParams(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   job,
+	})
+}
+
+// jobList atiende GET /api/v1/jobs: los trabajos de análisis en memoria, sin el análisis
+func (api *API) jobList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status": "success",
-		"data":   analysis,
+		"data":   api.jobs.Jobs(),
+	})
+}
+
+// job atiende GET /api/v1/jobs/{id} (estado, progreso, tiempos y análisis al terminar) y
+// POST /api/v1/jobs/{id}/cancel
+func (api *API) job(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/"), "/"), "/")
+
+	var job *model.Job
+	var err error
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		job, err = api.jobs.Job(parts[0])
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		job, err = api.jobs.Cancel(parts[0])
+	case len(parts) <= 2:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   job,
 	})
 }
 
@@ -54,7 +110,7 @@ func (api *API) BestNumbers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	numbers, scores, err := api.processor.BestNumbers(ctx, limit)
+	best, err := api.processor.BestNumbers(ctx, analysisParams(r), limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -64,13 +120,649 @@ func (api *API) BestNumbers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status": "success",
 		"data": map[string]interface{}{
-			"numbers": numbers,
-			"scores":  scores,
-			"count":   len(numbers),
+			"strategy":     best.Strategy,
+			"mode":         best.Mode,
+			"numbers":      best.Numbers,
+			"scores":       best.Scores,
+			"count":        len(best.Numbers),
+			"signs":        best.Signs,
+			"sign_scores":  best.SignScores,
+			"combinations": best.Combinations,
+			"ensemble":     best.Ensemble,
+			"baseline":     best.Baseline,
+			"entries":      best.Entries,
 		},
 	})
 }
 
+// wheel atiende GET /api/v1/analysis/wheel?budget=20&temperature=1&three_weight=1&two_weight=1 con
+// los mismos parámetros de análisis que best-numbers
+func (api *API) wheel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var params model.WheelParams
+	if budget, err := strconv.Atoi(query.Get("budget")); err == nil && budget > 0 {
+		params.Budget = budget
+	}
+	if temperature, err := strconv.ParseFloat(query.Get("temperature"), 64); err == nil && temperature > 0 {
+		params.Temperature = temperature
+	}
+	if weight, err := strconv.ParseFloat(query.Get("three_weight"), 64); err == nil && weight >= 0 {
+		params.ThreeWeight = weight
+	}
+	if weight, err := strconv.ParseFloat(query.Get("two_weight"), 64); err == nil && weight >= 0 {
+		params.TwoWeight = weight
+	}
+
+	ctx := r.Context()
+	result, err := api.processor.Wheel(ctx, analysisParams(r), params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// numberScore atiende GET /api/v1/numbers/{number}/score y GET /api/v1/numbers/{number}/ev?bet=four&sign=A
+func (api *API) numberScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/numbers/"), "/"), "/")
+	if len(parts) != 2 || (parts[1] != "score" && parts[1] != "ev") {
+		http.NotFound(w, r)
+		return
+	}
+
+	number, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts[0]) > 4 || number < 0 {
+		http.Error(w, "Invalid number", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	var data interface{}
+	if parts[1] == "ev" {
+		query := r.URL.Query()
+		ticket := model.Ticket{Number: number, Bet: betType(r), Sign: strings.ToUpper(query.Get("sign"))}
+		if stake, err := strconv.ParseFloat(query.Get("stake"), 64); err == nil && stake > 0 {
+			ticket.Stake = stake
+		}
+		data, err = api.processor.ExpectedValue(ctx, analysisParams(r), []model.Ticket{ticket}, temperature(r))
+	} else {
+		data, err = api.processor.CalculateProbability(ctx, analysisParams(r), number)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
+// bestNumbersValue atiende GET /api/v1/analysis/best-numbers/ev?bet=four&limit=10: el valor esperado de
+// apostar a cada uno de los mejores números; con four_sign se apuestan las mejores combinaciones
+func (api *API) bestNumbersValue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	ctx := r.Context()
+	params := analysisParams(r)
+	best, err := api.processor.BestNumbers(ctx, params, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bet := betType(r)
+	var tickets []model.Ticket
+	if bet == service.BetFourSign {
+		for _, combination := range best.Combinations {
+			tickets = append(tickets, model.Ticket{Number: combination.Number, Sign: combination.Sign, Bet: bet})
+		}
+	} else {
+		for _, number := range best.Numbers {
+			tickets = append(tickets, model.Ticket{Number: number, Bet: bet})
+		}
+	}
+
+	report, err := api.processor.ExpectedValue(ctx, params, tickets, temperature(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// expectedValue atiende GET /api/v1/analysis/ev?tickets=1234:four,0427:four_sign:A:2 con apuestas
+// número:apuesta[:signo][:valor] separadas por comas
+func (api *API) expectedValue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tickets, err := service.ParseTickets(strings.Split(r.URL.Query().Get("tickets"), ","))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.ExpectedValue(ctx, analysisParams(r), tickets, temperature(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// statistics atiende GET /api/v1/statistics?start=yyyy-mm-dd&end=yyyy-mm-dd
+func (api *API) statistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	stats, err := api.processor.Statistics(ctx, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   stats,
+	})
+}
+
+// patterns atiende GET /api/v1/analysis/patterns?start=yyyy-mm-dd&end=yyyy-mm-dd
+func (api *API) patterns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.PatternAnalysis(ctx, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// shape atiende GET /api/v1/analysis/shape?start=yyyy-mm-dd&end=yyyy-mm-dd
+func (api *API) shape(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.ShapeAnalysis(ctx, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// seasonality atiende GET /api/v1/analysis/seasonality?start=yyyy-mm-dd&end=yyyy-mm-dd
+func (api *API) seasonality(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.Seasonality(ctx, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// dependencies atiende GET /api/v1/analysis/dependencies?start=yyyy-mm-dd&end=yyyy-mm-dd&draws=&permutations=&seed=
+func (api *API) dependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	var params model.DependencyParams
+	if draws, err := strconv.Atoi(query.Get("draws")); err == nil && draws > 0 {
+		params.Draws = draws
+	}
+	if permutations, err := strconv.Atoi(query.Get("permutations")); err == nil && permutations > 0 {
+		params.Permutations = permutations
+	}
+	if seed, err := strconv.ParseInt(query.Get("seed"), 10, 64); err == nil {
+		params.Seed = seed
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.Dependencies(ctx, startDate, endDate, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// correlation atiende GET /api/v1/analysis/correlation?a=21&b=22&start=yyyy-mm-dd&end=yyyy-mm-dd
+func (api *API) correlation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lotteryA, errA := strconv.Atoi(r.URL.Query().Get("a"))
+	lotteryB, errB := strconv.Atoi(r.URL.Query().Get("b"))
+	if errA != nil || errB != nil {
+		http.Error(w, "Invalid lotteries, use a and b with the lottery ids", http.StatusBadRequest)
+		return
+	}
+
+	startDate, endDate, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.processor.Correlation(ctx, lotteryA, lotteryB, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// analyses atiende GET /api/v1/analyses?strategy=&start=&end=&limit=20&offset=0
+func (api *API) analyses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.AnalysisFilter{Strategy: query.Get("strategy")}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+	for name, target := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if value := query.Get(name); value != "" {
+			date, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s date: %s", name, value), http.StatusBadRequest)
+				return
+			}
+			*target = date
+		}
+	}
+
+	ctx := r.Context()
+	page, err := api.processor.Analyses(ctx, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   page,
+	})
+}
+
+// analysis atiende GET /api/v1/analyses/{id}, GET /api/v1/analyses/diff?from=1&to=2&mode=cold&top=100
+// y GET /api/v1/analyses/{id}/frequencies/{group}?pair=&triple=&position=
+func (api *API) analysis(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	var data interface{}
+	var err error
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/analyses/"), "/")
+	if path == "diff" {
+		query := r.URL.Query()
+		from, errFrom := strconv.Atoi(query.Get("from"))
+		to, errTo := strconv.Atoi(query.Get("to"))
+		if errFrom != nil || errTo != nil {
+			http.Error(w, "from and to must be analysis ids", http.StatusBadRequest)
+			return
+		}
+		top, _ := strconv.Atoi(query.Get("top"))
+		data, err = api.processor.DiffAnalyses(ctx, from, to, query.Get("mode"), top)
+	} else {
+		parts := strings.Split(path, "/")
+		id, convErr := strconv.Atoi(parts[0])
+		switch {
+		case convErr != nil:
+			http.NotFound(w, r)
+			return
+		case len(parts) == 1:
+			data, err = api.processor.AnalysisByID(ctx, id)
+		case len(parts) == 3 && parts[1] == "frequencies":
+			data, err = api.processor.AnalysisFrequencies(ctx, id, parts[2], frequencyTable(r))
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	}
+	if errors.Is(err, service.ErrAnalysisNotFound) || errors.Is(err, service.ErrFrequenciesNotFound) ||
+		errors.Is(err, service.ErrRankingNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
+// frequencyTable es la tabla pedida del grupo: pair en two-digit, triple en three-digit y position
+// en digit y sign; vacío son todas.
+func frequencyTable(r *http.Request) string {
+	query := r.URL.Query()
+	for _, key := range []string{"pair", "triple", "position", "table"} {
+		if value := query.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// tickets atiende POST /api/v1/tickets (registra una apuesta, date en yyyy-mm-dd) y
+// GET /api/v1/tickets?user=&strategy=&status=&lottery=&start=&end= (lista las apuestas)
+func (api *API) tickets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var data interface{}
+
+	switch r.Method {
+	case http.MethodPost:
+		// La fecha llega como yyyy-mm-dd, no con el formato completo de time.Time
+		var request struct {
+			model.PlayedTicket
+			Date string `json:"date"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid ticket", http.StatusBadRequest)
+			return
+		}
+		date, err := time.Parse(time.DateOnly, request.Date)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid date: %s", request.Date), http.StatusBadRequest)
+			return
+		}
+		ticket := request.PlayedTicket
+		ticket.Date = date
+
+		if err := api.ledger.Register(ctx, &ticket); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data = ticket
+	case http.MethodGet:
+		filter, err := ticketFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tickets, err := api.ledger.Tickets(ctx, filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = tickets
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
+// ticketSummary atiende GET /api/v1/tickets/summary?by=user|strategy con los filtros de tickets
+func (api *API) ticketSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := ticketFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, err := api.ledger.Summary(ctx, filter, r.URL.Query().Get("by"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
+
+// ticketFilter lee los filtros de apuestas de la query; sin start ni end no se limitan las fechas
+func ticketFilter(r *http.Request) (model.TicketFilter, error) {
+	query := r.URL.Query()
+	filter := model.TicketFilter{
+		User:     query.Get("user"),
+		Strategy: query.Get("strategy"),
+		Status:   query.Get("status"),
+	}
+	if lottery, err := strconv.Atoi(query.Get("lottery")); err == nil {
+		filter.Lottery = lottery
+	}
+
+	for name, target := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if value := query.Get(name); value != "" {
+			date, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s date: %s", name, value)
+			}
+			*target = date
+		}
+	}
+	return filter, nil
+}
+
+// dateRange lee start y end (yyyy-mm-dd) de la query; sin start se toma todo el histórico y sin end
+// hasta hoy.
+func dateRange(r *http.Request) (time.Time, time.Time, error) {
+	var startDate time.Time
+	endDate := time.Now()
+
+	if value := r.URL.Query().Get("start"); value != "" {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return startDate, endDate, fmt.Errorf("invalid start date: %s", value)
+		}
+		startDate = date
+	}
+
+	if value := r.URL.Query().Get("end"); value != "" {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return startDate, endDate, fmt.Errorf("invalid end date: %s", value)
+		}
+		endDate = date
+	}
+
+	if endDate.Before(startDate) {
+		return startDate, endDate, fmt.Errorf("end date is before start date")
+	}
+
+	return startDate, endDate, nil
+}
+
+// betType lee el tipo de apuesta de la query; por defecto las cuatro cifras
+func betType(r *http.Request) string {
+	if bet := r.URL.Query().Get("bet"); bet != "" {
+		return bet
+	}
+	return service.BetFour
+}
+
+// temperature lee la temperatura de la probabilidad modelada; 0 deja la del servicio
+func temperature(r *http.Request) float64 {
+	if value, err := strconv.ParseFloat(r.URL.Query().Get("temperature"), 64); err == nil && value > 0 {
+		return value
+	}
+	return 0
+}
+
+// analysisParams lee de la query los parámetros opcionales del análisis
+func analysisParams(r *http.Request) model.AnalysisParams {
+	query := r.URL.Query()
+	params := model.AnalysisParams{
+		Strategy:        query.Get("strategy"),
+		Mode:            query.Get("mode"),
+		Normalization:   query.Get("normalization"),
+		Patterns:        query.Get("patterns"),
+		ExcludePatterns: query.Get("exclude_patterns"),
+		Shape:           query.Get("shape"),
+		ShapeMode:       query.Get("shape_mode"),
+		AsOf:            query.Get("as_of"),
+		Cutoff:          query.Get("cutoff"),
+	}
+	if fitDraws, err := strconv.Atoi(query.Get("fit_draws")); err == nil && fitDraws > 0 {
+		params.FitDraws = fitDraws
+	}
+	if top, err := strconv.Atoi(query.Get("top")); err == nil && top > 0 {
+		params.TopNumbers = top
+	}
+	if simulations, err := strconv.Atoi(query.Get("simulations")); err == nil && simulations > 0 {
+		params.Simulations = simulations
+	}
+	if seed, err := strconv.ParseInt(query.Get("seed"), 10, 64); err == nil {
+		params.Seed = seed
+	}
+	if baselineDraws, err := strconv.Atoi(query.Get("baseline_draws")); err == nil && baselineDraws > 0 {
+		params.BaselineDraws = baselineDraws
+	}
+	if bootstrap, err := strconv.Atoi(query.Get("bootstrap")); err == nil && bootstrap > 0 {
+		params.Bootstrap = bootstrap
+	}
+	return params
+}
+
 func (api *API) healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -88,16 +780,42 @@ func main() {
 	}
 	defer db.Close()
 
-	resultRepo := repository.NewResultRepository(db)
-	scrapperService := service.NewScrapperService(resultRepo)
-	processorService := service.NewProcessorService(scrapperService, resultRepo)
+	if cfg.Database.MigrateOnStart {
+		migrateOnStart(db)
+	}
+
+	resultRepo := repository.NewResultRepository(db, cfg.Lottery.ID)
+	prizes, err := service.LoadPrizeTables(cfg.Lottery.PrizesFile)
+	if err != nil {
+		log.Fatal("Failed to load prize tables:", err)
+	}
+	ledgerService := service.NewLedgerService(repository.NewTicketRepository(db), resultRepo, prizes)
+	scrapperService := service.NewScrapperService(resultRepo, ledgerService)
+	processorService := service.NewProcessorService(scrapperService, resultRepo, prizes)
 
-	api := &API{processor: processorService}
+	jobService := service.NewJobService(processorService)
+	api := &API{processor: processorService, ledger: ledgerService, jobs: jobService}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", api.healthCheck)
 	mux.HandleFunc("/api/v1/analysis/process", api.processAnalysis)
+	mux.HandleFunc("/api/v1/jobs", api.jobList)
+	mux.HandleFunc("/api/v1/jobs/", api.job)
 	mux.HandleFunc("/api/v1/analysis/best-numbers", api.BestNumbers)
+	mux.HandleFunc("/api/v1/analysis/best-numbers/ev", api.bestNumbersValue)
+	mux.HandleFunc("/api/v1/analysis/ev", api.expectedValue)
+	mux.HandleFunc("/api/v1/analysis/wheel", api.wheel)
+	mux.HandleFunc("/api/v1/numbers/", api.numberScore)
+	mux.HandleFunc("/api/v1/statistics", api.statistics)
+	mux.HandleFunc("/api/v1/analysis/patterns", api.patterns)
+	mux.HandleFunc("/api/v1/analysis/shape", api.shape)
+	mux.HandleFunc("/api/v1/analysis/seasonality", api.seasonality)
+	mux.HandleFunc("/api/v1/analysis/dependencies", api.dependencies)
+	mux.HandleFunc("/api/v1/analysis/correlation", api.correlation)
+	mux.HandleFunc("/api/v1/analyses", api.analyses)
+	mux.HandleFunc("/api/v1/analyses/", api.analysis)
+	mux.HandleFunc("/api/v1/tickets", api.tickets)
+	mux.HandleFunc("/api/v1/tickets/summary", api.ticketSummary)
 
 	handler := middleware.CORS(middleware.Logging(middleware.Recovery(mux)))
 
@@ -128,5 +846,21 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Server shutdown failed:", err)
 	}
+	jobService.Close()
 	log.Println("Server stopped")
 }
+
+// migrateOnStart aplica las migraciones pendientes antes de atender peticiones (MIGRATE_ON_START).
+func migrateOnStart(db *sql.DB) {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Fatal("Migration failed:", err)
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+	}
+}
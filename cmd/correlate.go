@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"lottery-analyzer/internal/service"
+)
+
+// runCorrelation compara todo el histórico guardado de dos loterías: correlate <a> <b>.
+func runCorrelation(ctx context.Context, processor service.ProcessorService, a, b string) {
+	lotteryA, errA := strconv.Atoi(a)
+	lotteryB, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		log.Fatal("Usage: correlate <lottery a> <lottery b>")
+	}
+
+	report, err := processor.Correlation(ctx, lotteryA, lotteryB, time.Time{}, time.Now())
+	if err != nil {
+		log.Fatal("Correlation failed:", err)
+	}
+
+	log.Printf("Lotteries %d and %d, aligned draws: %d", report.LotteryA, report.LotteryB, report.AlignedDraws)
+	for _, position := range report.Positions {
+		log.Printf("%s/%s: chi2=%.2f p=%.4f (adjusted %.4f) r=%.4f p=%.4f same digit %d/%d (expected %.4f, p=%.4f)",
+			position.PositionA, position.PositionB, position.ChiSquare, position.PValue, position.AdjustedPValue,
+			position.Pearson, position.PearsonPValue, position.SameDigit.Matches, position.SameDigit.Trials,
+			position.SameDigit.ExpectedShare, position.SameDigit.PValue)
+	}
+	log.Printf("Same number: %d/%d (p=%.4f)", report.SameNumber.Matches, report.SameNumber.Trials, report.SameNumber.PValue)
+	log.Printf("Same sign: %d/%d (expected %.4f, p=%.4f)", report.SameSign.Matches, report.SameSign.Trials,
+		report.SameSign.ExpectedShare, report.SameSign.PValue)
+}
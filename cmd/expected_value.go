@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/service"
+)
+
+// runExpectedValue valora las apuestas número:apuesta[:signo][:valor] con la tabla de premios: ev.
+func runExpectedValue(ctx context.Context, processor service.ProcessorService, params model.AnalysisParams, entries []string, temperature float64) {
+	tickets, err := service.ParseTickets(entries)
+	if err != nil {
+		log.Fatal("Invalid tickets:", err)
+	}
+
+	report, err := processor.ExpectedValue(ctx, params, tickets, temperature)
+	if err != nil {
+		log.Fatal("Expected value failed:", err)
+	}
+
+	log.Printf("Expected value (%s, %s), lottery %d, temperature %.2f", report.Strategy, report.Mode, report.Lottery, report.Temperature)
+	for _, ticket := range report.Tickets {
+		log.Printf("%04d %s %s: p=%.6f (uniform %.6f), return %.4f (uniform %.4f) per unit",
+			ticket.Number, ticket.Bet, ticket.Sign, ticket.WinProbability, ticket.UniformWinProbability,
+			ticket.ExpectedReturn, ticket.UniformReturn)
+	}
+	log.Printf("Stake %.2f: expected payout %.4f (uniform %.4f), return %.4f (uniform %.4f) per unit",
+		report.Stake, report.ExpectedPayout, report.UniformPayout, report.ExpectedReturn, report.UniformReturn)
+}
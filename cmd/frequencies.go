@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/service"
+)
+
+// runFrequencies compara las frecuencias incrementales con el recálculo completo: frequencies verify.
+func runFrequencies(ctx context.Context, processor service.ProcessorService, params model.AnalysisParams, args []string) {
+	if len(args) != 1 || args[0] != "verify" {
+		log.Fatal("Usage: frequencies verify")
+	}
+
+	check, err := processor.VerifyFrequencies(ctx, params)
+	if err != nil {
+		log.Fatal("Frequency check failed:", err)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TABLE\tCELLS\tMISMATCHES\tMAX DIFF")
+	for _, t := range check.Tables {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%g\n", t.Table, t.Cells, t.Mismatches, t.MaxDiff)
+	}
+	table.Flush()
+
+	log.Printf("As of %s, cutoff %s, %d windows, incremental state %s", check.AsOf, check.Cutoff, check.Windows, check.Incremental)
+	if !check.Consistent {
+		log.Fatal("Incremental frequencies differ from the full recomputation")
+	}
+	log.Println("Incremental frequencies match the full recomputation")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/service"
+)
+
+// runHistory lista los análisis guardados: history.
+func runHistory(ctx context.Context, processor service.ProcessorService, filter model.AnalysisFilter) {
+	page, err := processor.Analyses(ctx, filter)
+	if err != nil {
+		log.Fatal("History failed:", err)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tAS OF\tSTRATEGY\tLAST RESULT\tDRAWS\tCREATED")
+	for _, record := range page.Analyses {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%d\t%s\n", record.ID, record.Key.AsOf, record.Key.Strategy,
+			record.Key.Fingerprint.LastDate, record.Key.Fingerprint.Count, record.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	table.Flush()
+	log.Printf("Showing %d of %d analyses from offset %d", len(page.Analyses), page.Total, page.Offset)
+}
+
+// runDiff imprime como tabla los cambios del top-N entre dos análisis guardados: diff <from> <to>.
+func runDiff(ctx context.Context, processor service.ProcessorService, from, to, mode string, top int) {
+	fromID, errFrom := strconv.Atoi(from)
+	toID, errTo := strconv.Atoi(to)
+	if errFrom != nil || errTo != nil {
+		log.Fatal("Usage: diff <from analysis id> <to analysis id>")
+	}
+
+	diff, err := processor.DiffAnalyses(ctx, fromID, toID, mode, top)
+	if err != nil {
+		log.Fatal("Diff failed:", err)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "CHANGE\tNUMBER\tFROM\tTO\tMOVE\tFROM SCORE\tTO SCORE\tDELTA\t")
+	for _, group := range []struct {
+		name    string
+		changes []model.RankChange
+	}{{"entered", diff.Entered}, {"left", diff.Left}, {"moved", diff.Moved}} {
+		for _, c := range group.changes {
+			fmt.Fprintf(table, "%s\t%04d\t%s\t%s\t%+d\t%s\t%s\t%s\t\n", group.name, c.Number,
+				rankCell(c.FromRank), rankCell(c.ToRank), c.Movement,
+				scoreCell(c.FromScore), scoreCell(c.ToScore), scoreCell(c.ScoreDelta))
+		}
+	}
+	table.Flush()
+	log.Printf("Top %d (%s) from #%d to #%d: %d kept, %d entered, %d left, %d moved",
+		diff.TopN, diff.Mode, diff.From, diff.To, diff.Kept, len(diff.Entered), len(diff.Left), len(diff.Moved))
+}
+
+func rankCell(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return strconv.Itoa(rank)
+}
+
+func scoreCell(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.6f", *score)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"lottery-analyzer/internal/config"
+	"lottery-analyzer/internal/model"
 	"lottery-analyzer/internal/repository"
 	"lottery-analyzer/internal/service"
 	"lottery-analyzer/pkg/database"
@@ -17,6 +19,29 @@ import (
 )
 
 func main() {
+	strategy := flag.String("strategy", service.StrategyFrequency, "estrategia de puntuación (frequency, single, pair, triple, full, ensemble, weekday)")
+	mode := flag.String("mode", service.ModeCold, "modo de selección de los mejores números (cold, hot, distance)")
+	normalization := flag.String("normalization", "", "normalización del ensemble (rank, zscore)")
+	fitDraws := flag.Int("fit-draws", 0, "sorteos usados para ajustar los pesos del ensemble")
+	simulations := flag.Int("simulations", 0, "selecciones aleatorias para la comparación contra el azar")
+	seed := flag.Int64("seed", 0, "semilla de la simulación")
+	patterns := flag.String("patterns", "", "clases de patrón permitidas, separadas por coma")
+	excludePatterns := flag.String("exclude-patterns", "", "clases de patrón excluidas, separadas por coma")
+	shape := flag.String("shape", "", "perfil de forma preferido, p. ej. sum:10-20,even:2,high:1-3")
+	shapeMode := flag.String("shape-mode", "", "uso del perfil de forma (filter, prefer)")
+	top := flag.Int("top", 100, "cantidad de mejores números a calcular")
+	asOf := flag.String("as-of", "", "día del análisis (yyyy-mm-dd), por defecto hoy")
+	cutoff := flag.String("cutoff", "", "último día de sorteos que se tienen en cuenta (yyyy-mm-dd), por defecto el del análisis")
+	bootstrap := flag.Int("bootstrap", 0, "remuestreos del histórico para medir la estabilidad del ranking")
+	budget := flag.Int("budget", 10, "números a jugar en el comando wheel (hasta 200)")
+	temperature := flag.Float64("temperature", 1, "temperatura de la probabilidad modelada en los comandos wheel y ev")
+	user := flag.String("user", "", "usuario de las apuestas en el comando ticket")
+	drawDate := flag.String("draw-date", "", "día del sorteo (yyyy-mm-dd) de las apuestas en ticket add, por defecto hoy")
+	by := flag.String("by", service.LedgerByUser, "agrupación del resumen de apuestas (user, strategy)")
+	limit := flag.Int("limit", 20, "análisis por página en el comando history")
+	offset := flag.Int("offset", 0, "análisis a saltar en el comando history")
+	flag.Parse()
+
 	cfg := config.Load()
 
 	db, err := database.NewMySQL(cfg.Database.DSN)
@@ -25,9 +50,23 @@ func main() {
 	}
 	defer db.Close()
 
-	resultRepo := repository.NewResultRepository(db)
-	scrapperService := service.NewScrapperService(resultRepo)
-	processorService := service.NewProcessorService(scrapperService, resultRepo)
+	// El comando migrate decide qué migraciones aplicar; el resto aplica las pendientes si se pidió
+	if flag.Arg(0) == "migrate" {
+		runMigrate(db, flag.Args()[1:])
+		return
+	}
+	if cfg.Database.MigrateOnStart {
+		runMigrate(db, []string{"up"})
+	}
+
+	resultRepo := repository.NewResultRepository(db, cfg.Lottery.ID)
+	prizes, err := service.LoadPrizeTables(cfg.Lottery.PrizesFile)
+	if err != nil {
+		log.Fatal("Failed to load prize tables:", err)
+	}
+	ledgerService := service.NewLedgerService(repository.NewTicketRepository(db), resultRepo, prizes)
+	scrapperService := service.NewScrapperService(resultRepo, ledgerService)
+	processorService := service.NewProcessorService(scrapperService, resultRepo, prizes)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -41,17 +80,110 @@ func main() {
 		cancel()
 	}()
 
+	params := model.AnalysisParams{
+		Strategy:        *strategy,
+		Mode:            *mode,
+		Normalization:   *normalization,
+		FitDraws:        *fitDraws,
+		Simulations:     *simulations,
+		Seed:            *seed,
+		Patterns:        *patterns,
+		ExcludePatterns: *excludePatterns,
+		Shape:           *shape,
+		ShapeMode:       *shapeMode,
+		TopNumbers:      *top,
+		AsOf:            *asOf,
+		Cutoff:          *cutoff,
+		Bootstrap:       *bootstrap,
+	}
+
+	// Sin comando se ejecuta el análisis completo
+	switch command := flag.Arg(0); command {
+	case "":
+	case "correlate":
+		runCorrelation(ctx, processorService, flag.Arg(1), flag.Arg(2))
+		return
+	case "wheel":
+		runWheel(ctx, processorService, params, model.WheelParams{Budget: *budget, Temperature: *temperature})
+		return
+	case "history":
+		filter := model.AnalysisFilter{Limit: *limit, Offset: *offset}
+		if strategySet() {
+			filter.Strategy = *strategy
+		}
+		runHistory(ctx, processorService, filter)
+		return
+	case "diff":
+		runDiff(ctx, processorService, flag.Arg(1), flag.Arg(2), *mode, *top)
+		return
+	case "ticket":
+		filter := model.TicketFilter{Lottery: cfg.Lottery.ID, User: *user}
+		if strategySet() {
+			filter.Strategy = *strategy
+		}
+		runTicket(ctx, ledgerService, flag.Args()[1:], filter, *drawDate, *by)
+		return
+	case "frequencies":
+		runFrequencies(ctx, processorService, params, flag.Args()[1:])
+		return
+	case "ev":
+		runExpectedValue(ctx, processorService, params, flag.Args()[1:], *temperature)
+		return
+	default:
+		log.Fatalf("Unknown command: %s", command)
+	}
+
 	log.Println("Starting lottery analysis...")
 	start := time.Now()
 
-	analysis, err := processorService.ProcessAnalysis(ctx)
+	analysis, err := processorService.ProcessAnalysis(ctx, params)
 	if err != nil {
 		log.Fatal("Analysis failed:", err)
 	}
 
 	log.Printf("Analysis completed in %v", time.Since(start))
-	log.Printf("Best numbers: %v", analysis.BestNumbers[:10])
+	first := analysis.BestNumbers
+	if len(first) > 10 {
+		first = first[:10]
+	}
+	log.Printf("Best numbers (%s): %v", analysis.Mode, first)
+	if len(analysis.BestSigns) > 0 {
+		log.Printf("Best sign: %s", analysis.BestSigns[0])
+	}
+	if len(analysis.BestCombinations) > 0 {
+		log.Printf("Best combination: %04d %s", analysis.BestCombinations[0].Number, analysis.BestCombinations[0].Sign)
+	}
 	log.Printf("Total processed: %d", analysis.TotalProcessed)
 	log.Printf("Days grouped by fibonacci analyzed : %d", analysis.GroupDaysAnalyzed)
 	log.Printf("Numbers without occurrences : %d", analysis.UnplayedCount)
+	if analysis.Baseline != nil {
+		for _, level := range analysis.Baseline.Levels {
+			log.Printf("Hits %s: %d (random %.2f ± %.2f, p=%.4f)", level.Match, level.Hits, level.RandomMean, level.RandomStd, level.PValue)
+		}
+		log.Printf("Better than random: %t", analysis.Baseline.BetterThanRandom)
+	}
+	if analysis.Ensemble != nil {
+		log.Printf("Ensemble weights: %v", analysis.Ensemble.Weights)
+	}
+	stability := analysis.Stability
+	if len(stability) > len(first) {
+		stability = stability[:len(first)]
+	}
+	for _, entry := range stability {
+		log.Printf("Stability %04d: top10 %.0f%% top50 %.0f%% top100 %.0f%% median rank %d (p5-p95 %d-%d)",
+			entry.Number, entry.Top10*100, entry.Top50*100, entry.Top100*100,
+			entry.RankP50, entry.RankP05, entry.RankP95)
+	}
+}
+
+// strategySet indica si se pasó -strategy; la estrategia por defecto no filtra los listados ni se
+// guarda en las apuestas.
+func strategySet() bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "strategy" {
+			set = true
+		}
+	})
+	return set
 }
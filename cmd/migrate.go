@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"lottery-analyzer/internal/migration"
+)
+
+// runMigrate aplica, deshace o lista las migraciones embebidas: migrate up|down [n]|status.
+func runMigrate(db *sql.DB, args []string) {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate up|down [n]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		}
+		if len(applied) == 0 {
+			log.Println("Schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				log.Fatal("Usage: migrate down [n]")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		for _, m := range reverted {
+			log.Printf("Reverted migration %d_%s", m.Version, m.Name)
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("Failed to get migration status:", err)
+		}
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "VERSION\tNAME\tAPPLIED")
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(table, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		table.Flush()
+	default:
+		log.Fatal("Usage: migrate up|down [n]|status")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/service"
+)
+
+// runTicket registra y consulta las apuestas compradas: ticket add <número:apuesta[:signo][:valor]>...,
+// ticket list y ticket summary.
+func runTicket(ctx context.Context, ledger service.LedgerService, args []string, filter model.TicketFilter, drawDate, by string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: ticket add|list|summary")
+	}
+
+	switch args[0] {
+	case "add":
+		addTickets(ctx, ledger, args[1:], filter, drawDate)
+	case "list":
+		tickets, err := ledger.Tickets(ctx, filter)
+		if err != nil {
+			log.Fatal("Ticket list failed:", err)
+		}
+		for _, ticket := range tickets {
+			logTicket(ticket)
+		}
+	case "summary":
+		report, err := ledger.Summary(ctx, filter, by)
+		if err != nil {
+			log.Fatal("Ticket summary failed:", err)
+		}
+		for _, group := range report.Groups {
+			logSummary(report.By, group)
+		}
+		logSummary(report.By, report.Total)
+	default:
+		log.Fatalf("Unknown ticket command: %s", args[0])
+	}
+}
+
+func addTickets(ctx context.Context, ledger service.LedgerService, entries []string, filter model.TicketFilter, drawDate string) {
+	date := time.Now()
+	if drawDate != "" {
+		parsed, err := time.Parse(time.DateOnly, drawDate)
+		if err != nil {
+			log.Fatalf("Invalid draw date: %s", drawDate)
+		}
+		date = parsed
+	}
+
+	tickets, err := service.ParseTickets(entries)
+	if err != nil {
+		log.Fatal("Invalid tickets:", err)
+	}
+
+	for _, ticket := range tickets {
+		if ticket.Stake <= 0 {
+			ticket.Stake = 1
+		}
+		played := &model.PlayedTicket{
+			Lottery:  filter.Lottery,
+			User:     filter.User,
+			Strategy: filter.Strategy,
+			Date:     date,
+			Ticket:   ticket,
+		}
+		if err := ledger.Register(ctx, played); err != nil {
+			log.Fatal("Ticket register failed:", err)
+		}
+		logTicket(played)
+	}
+}
+
+func logTicket(ticket *model.PlayedTicket) {
+	log.Printf("#%d %s %s %04d %s %s stake %.2f: %s, payout %.2f",
+		ticket.ID, ticket.Date.Format(time.DateOnly), ticket.User, ticket.Number, ticket.Bet, ticket.Sign,
+		ticket.Stake, ticket.Status, ticket.Payout)
+}
+
+func logSummary(by string, summary model.LedgerSummary) {
+	log.Printf("%s %s: %d tickets (%d won, %d lost, %d pending), stake %.2f, payout %.2f, net %.2f, ROI %.2f%%",
+		by, summary.Group, summary.Tickets, summary.Won, summary.Lost, summary.Pending,
+		summary.Stake, summary.Payout, summary.Net, summary.ROI*100)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/service"
+)
+
+// runWheel elige los números a jugar que más terminaciones cubren: wheel.
+func runWheel(ctx context.Context, processor service.ProcessorService, params model.AnalysisParams, wheel model.WheelParams) {
+	result, err := processor.Wheel(ctx, params, wheel)
+	if err != nil {
+		log.Fatal("Wheel failed:", err)
+	}
+
+	log.Printf("Wheel (%s, %s), budget %d, temperature %.2f", result.Strategy, result.Mode, result.Params.Budget, result.Params.Temperature)
+	for i, number := range result.Numbers {
+		log.Printf("%04d p=%.6f", number, result.Probabilities[i])
+	}
+
+	logCoverage("Wheel", result.Coverage)
+	logCoverage("Top", result.TopCoverage)
+	log.Printf("Local search swaps: %d", result.Swaps)
+}
+
+func logCoverage(name string, coverage model.WheelCoverage) {
+	log.Printf("%s: exact %.4f, last three %.4f (%d endings), last two %.4f (%d endings)",
+		name, coverage.Exact, coverage.LastThree, coverage.DistinctLastThree,
+		coverage.LastTwo, coverage.DistinctLastTwo)
+}
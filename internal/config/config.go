@@ -11,12 +11,15 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	Scrapper ScrapperConfig
+	Lottery  LotteryConfig
 }
 
+// DatabaseConfig.MigrateOnStart aplica las migraciones pendientes al arrancar.
 type DatabaseConfig struct {
-	DSN          string
-	MaxOpenConns int
-	MaxIdleConns int
+	DSN            string
+	MaxOpenConns   int
+	MaxIdleConns   int
+	MigrateOnStart bool
 }
 
 type ServerConfig struct {
@@ -28,12 +31,20 @@ type ScrapperConfig struct {
 	Timeout int
 }
 
+// LotteryConfig es la lotería con la que trabajan el scrapping y el análisis (idLoteria en la web).
+// PrizesFile es el JSON con las tablas de premios; sin él se usa la tabla de referencia.
+type LotteryConfig struct {
+	ID         int
+	PrizesFile string
+}
+
 func Load() *Config {
 	return &Config{
 		Database: DatabaseConfig{
-			DSN:          getEnv("DATABASE_DSN", ""),
-			MaxOpenConns: getEnvInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns: getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			DSN:            getEnv("DATABASE_DSN", ""),
+			MaxOpenConns:   getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:   getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			MigrateOnStart: getEnvBool("MIGRATE_ON_START", false),
 		},
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "5000"), // Default port for the API server if .env is not set
@@ -42,6 +53,10 @@ func Load() *Config {
 			BaseURL: getEnv("SCRAPPER_BASE_URL", "https://resultadodelaloteria.com"),
 			Timeout: getEnvInt("SCRAPPER_TIMEOUT", 30),
 		},
+		Lottery: LotteryConfig{
+			ID:         getEnvInt("LOTTERY_ID", 21),
+			PrizesFile: getEnv("PRIZES_FILE", ""),
+		},
 	}
 }
 
@@ -60,3 +75,12 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
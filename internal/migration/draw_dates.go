@@ -0,0 +1,218 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// textDateLayout es el dd/mm/yyyy con el que se guardaban las fechas como texto.
+const textDateLayout = "02/01/2006"
+
+// maxReportedDates limita las filas con fecha inválida que se listan en el error.
+const maxReportedDates = 20
+
+// dateColumn es una columna date guardada como texto que pasa a DATE, con el índice que la usa.
+type dateColumn struct {
+	table   string
+	after   string
+	index   string
+	columns string
+}
+
+var dateColumns = []dateColumn{
+	{table: "result", after: "version", index: "idx_result_lottery_date", columns: "lottery, date"},
+	{table: "ticket", after: "strategy", index: "idx_ticket_pending", columns: "lottery, date, status"},
+}
+
+// dateConversion es el paso de la columna date a otro tipo a través de una columna auxiliar.
+type dateConversion struct {
+	dataType string // tipo de information_schema al que se llega
+	sqlType  string // tipo de la columna nueva
+	temp     string // columna auxiliar con los valores convertidos
+	value    string // expresión que convierte date
+}
+
+var (
+	toDate = dateConversion{dataType: "date", sqlType: "DATE", temp: "date_new",
+		value: "STR_TO_DATE(TRIM(date), '%d/%m/%Y')"}
+	toText = dateConversion{dataType: "varchar", sqlType: "VARCHAR(10)", temp: "date_text",
+		value: "DATE_FORMAT(date, '%d/%m/%Y')"}
+)
+
+// drawDatesUp pasa las fechas de result y ticket de dd/mm/yyyy a DATE. Antes de tocar nada revisa
+// todas las filas; si alguna fecha no se puede convertir falla listándolas, sin cambiar el esquema.
+func drawDatesUp(ctx context.Context, conn *sql.Conn) error {
+	var invalid []string
+	invalidCount := 0
+	for _, column := range dateColumns {
+		dataType, err := columnType(ctx, conn, column.table, "date")
+		if err != nil {
+			return err
+		}
+		if dataType == toDate.dataType {
+			continue
+		}
+
+		rows, err := unparsableDates(ctx, conn, column.table)
+		if err != nil {
+			return err
+		}
+		invalidCount += len(rows)
+		for _, row := range rows {
+			if len(invalid) < maxReportedDates {
+				invalid = append(invalid, row)
+			}
+		}
+	}
+
+	if invalidCount > 0 {
+		return fmt.Errorf("%d rows have dates that are not dd/mm/yyyy, fix them and run the migration again:\n%s",
+			invalidCount, strings.Join(invalid, "\n"))
+	}
+
+	for _, column := range dateColumns {
+		if err := convertColumn(ctx, conn, column, toDate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawDatesDown vuelve a guardar las fechas como texto dd/mm/yyyy.
+func drawDatesDown(ctx context.Context, conn *sql.Conn) error {
+	for _, column := range dateColumns {
+		if err := convertColumn(ctx, conn, column, toText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertColumn llena la columna auxiliar, comprueba que se convirtieron todas las filas y recién
+// entonces reemplaza date en un único ALTER, así una falla nunca deja la tabla sin fechas. Cada paso
+// mira information_schema y se saltea si ya se hizo, para poder volver a ejecutar la migración.
+func convertColumn(ctx context.Context, conn *sql.Conn, column dateColumn, to dateConversion) error {
+	dataType, err := columnType(ctx, conn, column.table, "date")
+	if err != nil {
+		return err
+	}
+	tempExists, err := columnExists(ctx, conn, column.table, to.temp)
+	if err != nil {
+		return err
+	}
+
+	if dataType != to.dataType {
+		// Una ejecución anterior pudo dejar la columna auxiliar creada y llena en parte
+		var statements []string
+		if !tempExists {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s NULL",
+				column.table, to.temp, to.sqlType))
+		}
+		statements = append(statements, fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL",
+			column.table, to.temp, to.value, to.temp))
+		if err := execAll(ctx, conn, column, statements); err != nil {
+			return err
+		}
+
+		if err := checkConverted(ctx, conn, column.table, to.temp); err != nil {
+			return err
+		}
+
+		indexed, err := indexExists(ctx, conn, column.table, column.index)
+		if err != nil {
+			return err
+		}
+		if err := execAll(ctx, conn, column, []string{swapStatement(column, to, indexed)}); err != nil {
+			return err
+		}
+	}
+
+	// El índice se crea aparte del reemplazo; si falta, también cuando date ya tenía el tipo pedido
+	indexed, err := indexExists(ctx, conn, column.table, column.index)
+	if err != nil || indexed {
+		return err
+	}
+	return execAll(ctx, conn, column, []string{
+		fmt.Sprintf("ALTER TABLE %s ADD KEY %s (%s)", column.table, column.index, column.columns),
+	})
+}
+
+// swapStatement reemplaza date por la columna auxiliar en un solo ALTER, junto con el índice viejo.
+func swapStatement(column dateColumn, to dateConversion, indexed bool) string {
+	var changes []string
+	if indexed {
+		changes = append(changes, "DROP INDEX "+column.index)
+	}
+	changes = append(changes, "DROP COLUMN date",
+		fmt.Sprintf("CHANGE COLUMN %s date %s NOT NULL AFTER %s", to.temp, to.sqlType, column.after))
+	return fmt.Sprintf("ALTER TABLE %s %s", column.table, strings.Join(changes, ", "))
+}
+
+// checkConverted falla si alguna fila quedó sin valor en la columna auxiliar.
+func checkConverted(ctx context.Context, conn *sql.Conn, table, temp string) error {
+	var total, converted int
+	query := fmt.Sprintf("SELECT COUNT(*), COUNT(%s) FROM %s", temp, table)
+	if err := conn.QueryRowContext(ctx, query).Scan(&total, &converted); err != nil {
+		return fmt.Errorf("failed to count converted %s dates: %w", table, err)
+	}
+	if converted != total {
+		return fmt.Errorf("only %d of %d %s dates were converted, %s.date was not replaced", converted, total, table, table)
+	}
+	return nil
+}
+
+func execAll(ctx context.Context, conn *sql.Conn, column dateColumn, statements []string) error {
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to convert %s.date: %w\n%s", column.table, err, statement)
+		}
+	}
+	return nil
+}
+
+// unparsableDates devuelve las filas de table cuya fecha no es dd/mm/yyyy, como "table id=... date=...".
+func unparsableDates(ctx context.Context, conn *sql.Conn, table string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT id, date FROM %s", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s dates: %w", table, err)
+	}
+	defer rows.Close()
+
+	var invalid []string
+	for rows.Next() {
+		var id int
+		var date string
+		if err := rows.Scan(&id, &date); err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(textDateLayout, strings.TrimSpace(date)); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s id=%d date=%q", table, id, date))
+		}
+	}
+	return invalid, rows.Err()
+}
+
+func columnType(ctx context.Context, conn *sql.Conn, table, column string) (string, error) {
+	query := `SELECT LOWER(DATA_TYPE) FROM information_schema.COLUMNS
+              WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`
+
+	var dataType string
+	if err := conn.QueryRowContext(ctx, query, table, column).Scan(&dataType); err != nil {
+		return "", fmt.Errorf("failed to get type of %s.%s: %w", table, column, err)
+	}
+	return dataType, nil
+}
+
+func indexExists(ctx context.Context, conn *sql.Conn, table, index string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.STATISTICS
+              WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`
+
+	var count int
+	if err := conn.QueryRowContext(ctx, query, table, index).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up index %s: %w", index, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"only comments", "-- nada\n\n  -- tampoco\n", nil},
+		{"one per line", "DROP TABLE a;\nDROP TABLE b;\n", []string{"DROP TABLE a", "DROP TABLE b"}},
+		{"several lines", "CREATE TABLE a (\n  id INT\n);\n", []string{"CREATE TABLE a (\n  id INT\n)"}},
+		{"comments inside", "-- a\nCREATE TABLE a (\n  -- id\n  id INT\n);", []string{"CREATE TABLE a (\n  id INT\n)"}},
+		{"semicolon inside a line", "UPDATE a SET b = ';' WHERE c = 1;", []string{"UPDATE a SET b = ';' WHERE c = 1"}},
+		{"last without semicolon", "DROP TABLE a;\nDROP TABLE b", []string{"DROP TABLE a", "DROP TABLE b"}},
+		{"windows line endings", "DROP TABLE a;\r\nDROP TABLE b;\r\n", []string{"DROP TABLE a", "DROP TABLE b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statements(tt.script)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("statements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version    int
+		name       string
+		reversible bool
+	}{
+		{1, "initial", false},
+		{2, "schema_columns", false},
+		{3, "draw_dates", true},
+	}
+	if len(migrations) != len(tests) {
+		t.Fatalf("loaded %d migrations, want %d", len(migrations), len(tests))
+	}
+	for i, tt := range tests {
+		m := migrations[i]
+		if m.Version != tt.version || m.Name != tt.name {
+			t.Errorf("migration %d is %d_%s, want %d_%s", i, m.Version, m.Name, tt.version, tt.name)
+		}
+		if reversible := m.Down != "" || m.DownFunc != nil; reversible != tt.reversible {
+			t.Errorf("migration %d_%s reversible = %v, want %v", m.Version, m.Name, reversible, tt.reversible)
+		}
+	}
+}
+
+// La migración 2 tiene que dejar las tablas viejas igual que las crea la migración 1.
+func TestSchemaColumnsMatchInitial(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := make(map[string]string)
+	for _, statement := range statements(migrations[0].Up) {
+		header, _, _ := strings.Cut(statement, "\n")
+		name := strings.TrimSuffix(strings.TrimPrefix(header, "CREATE TABLE IF NOT EXISTS "), " (")
+		tables[name] = statement
+	}
+
+	for _, column := range addedColumns {
+		line := fmt.Sprintf("\n  %s %s,", column.name, column.definition)
+		if !strings.Contains(tables[column.table], line) {
+			t.Errorf("%s.%s %s is not in the initial schema", column.table, column.name, column.definition)
+		}
+	}
+	for _, index := range addedIndexes {
+		line := fmt.Sprintf("\n  KEY %s (%s)", index.name, index.columns)
+		if !strings.Contains(tables[index.table], line) {
+			t.Errorf("index %s (%s) is not in the initial schema of %s", index.name, index.columns, index.table)
+		}
+	}
+}
+
+func TestSwapStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		column  dateColumn
+		to      dateConversion
+		indexed bool
+		want    string
+	}{
+		{"to date with index", dateColumns[0], toDate, true,
+			"ALTER TABLE result DROP INDEX idx_result_lottery_date, DROP COLUMN date, CHANGE COLUMN date_new date DATE NOT NULL AFTER version"},
+		{"to date without index", dateColumns[1], toDate, false,
+			"ALTER TABLE ticket DROP COLUMN date, CHANGE COLUMN date_new date DATE NOT NULL AFTER strategy"},
+		{"back to text", dateColumns[0], toText, true,
+			"ALTER TABLE result DROP INDEX idx_result_lottery_date, DROP COLUMN date, CHANGE COLUMN date_text date VARCHAR(10) NOT NULL AFTER version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := swapStatement(tt.column, tt.to, tt.indexed); got != tt.want {
+				t.Errorf("swapStatement =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// schemaColumn es una columna que las bases creadas antes de las migraciones pueden no tener.
+type schemaColumn struct {
+	table      string
+	name       string
+	definition string
+}
+
+// schemaIndex es un índice que las bases creadas antes de las migraciones pueden no tener.
+type schemaIndex struct {
+	table   string
+	name    string
+	columns string
+}
+
+// addedColumns son las columnas que se fueron agregando a mano con los ALTER del README.
+var addedColumns = []schemaColumn{
+	{table: "result", name: "lottery", definition: "INT NOT NULL DEFAULT 21"},
+	{table: "analysis", name: "lottery", definition: "INT NOT NULL DEFAULT 21"},
+	{table: "analysis", name: "strategy", definition: "VARCHAR(32) NOT NULL DEFAULT ''"},
+	{table: "analysis", name: "params_hash", definition: "CHAR(64) NOT NULL DEFAULT ''"},
+	{table: "analysis", name: "as_of", definition: "DATE NULL"},
+	{table: "analysis", name: "last_result_id", definition: "INT NOT NULL DEFAULT 0"},
+	{table: "analysis", name: "last_result_date", definition: "VARCHAR(10) NOT NULL DEFAULT ''"},
+	{table: "analysis", name: "result_count", definition: "INT NOT NULL DEFAULT 0"},
+	{table: "analysis", name: "frequencies", definition: "MEDIUMBLOB NULL"},
+}
+
+// addedIndexes son los índices de las consultas de los repositorios.
+var addedIndexes = []schemaIndex{
+	{table: "result", name: "idx_result_lottery_date", columns: "lottery, date"},
+	{table: "result", name: "idx_result_lottery_id", columns: "lottery, id"},
+	{table: "analysis", name: "idx_analysis_key", columns: "lottery, strategy, params_hash, as_of"},
+	{table: "analysis", name: "idx_analysis_as_of", columns: "lottery, as_of"},
+	{table: "ticket", name: "idx_ticket_pending", columns: "lottery, date, status"},
+	{table: "ticket", name: "idx_ticket_user", columns: "lottery, username"},
+}
+
+// schemaColumnsUp completa las tablas que ya existían antes de la migración 1, que con IF NOT EXISTS
+// las deja como estaban: agrega cada columna e índice que falte. Cada paso mira information_schema,
+// así se puede volver a ejecutar si falla a mitad de camino.
+func schemaColumnsUp(ctx context.Context, conn *sql.Conn) error {
+	for _, column := range addedColumns {
+		exists, err := columnExists(ctx, conn, column.table, column.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		statement := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", column.table, column.name, column.definition)
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w\n%s", column.table, column.name, err, statement)
+		}
+	}
+
+	for _, index := range addedIndexes {
+		exists, err := indexExists(ctx, conn, index.table, index.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		statement := fmt.Sprintf("ALTER TABLE %s ADD KEY %s (%s)", index.table, index.name, index.columns)
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to add index %s: %w\n%s", index.name, err, statement)
+		}
+	}
+	return nil
+}
+
+func columnExists(ctx context.Context, conn *sql.Conn, table, column string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.COLUMNS
+              WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`
+
+	var count int
+	if err := conn.QueryRowContext(ctx, query, table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up column %s.%s: %w", table, column, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,65 @@
+-- Esquema inicial: las tablas que usan los repositorios, con los índices de sus consultas.
+-- Con IF NOT EXISTS sólo se crean las tablas que faltan; en una base creada a mano con el README las
+-- que ya existen quedan como estaban y la migración 2 les agrega las columnas e índices que falten.
+-- No tiene down: deshacerla borraría los sorteos guardados antes de las migraciones.
+
+CREATE TABLE IF NOT EXISTS result (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  lottery INT NOT NULL DEFAULT 21,
+  version INT NOT NULL DEFAULT 0,
+  date VARCHAR(10) NOT NULL,
+  first TINYINT NOT NULL,
+  second TINYINT NOT NULL,
+  third TINYINT NOT NULL,
+  fourth TINYINT NOT NULL,
+  sign VARCHAR(1) NOT NULL DEFAULT 'Z',
+  KEY idx_result_lottery_date (lottery, date),
+  KEY idx_result_lottery_id (lottery, id)
+);
+
+CREATE TABLE IF NOT EXISTS analysis (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  lottery INT NOT NULL DEFAULT 21,
+  strategy VARCHAR(32) NOT NULL DEFAULT '',
+  params_hash CHAR(64) NOT NULL DEFAULT '',
+  as_of DATE NULL,
+  last_result_id INT NOT NULL DEFAULT 0,
+  last_result_date VARCHAR(10) NOT NULL DEFAULT '',
+  result_count INT NOT NULL DEFAULT 0,
+  data MEDIUMBLOB NOT NULL,
+  frequencies MEDIUMBLOB NULL,
+  created_at DATETIME NOT NULL,
+  KEY idx_analysis_key (lottery, strategy, params_hash, as_of),
+  KEY idx_analysis_as_of (lottery, as_of)
+);
+
+CREATE TABLE IF NOT EXISTS ticket (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  lottery INT NOT NULL,
+  username VARCHAR(64) NOT NULL,
+  strategy VARCHAR(32) NOT NULL DEFAULT '',
+  date VARCHAR(10) NOT NULL,
+  number INT NOT NULL,
+  sign VARCHAR(1) NOT NULL DEFAULT '',
+  bet VARCHAR(16) NOT NULL,
+  stake DOUBLE NOT NULL,
+  status VARCHAR(8) NOT NULL DEFAULT 'pending',
+  payout DOUBLE NOT NULL DEFAULT 0,
+  result_id INT NULL,
+  created_at DATETIME NOT NULL,
+  settled_at DATETIME NULL,
+  KEY idx_ticket_pending (lottery, date, status),
+  KEY idx_ticket_user (lottery, username)
+);
+
+CREATE TABLE IF NOT EXISTS frequency_state (
+  lottery INT PRIMARY KEY,
+  as_of DATE NOT NULL,
+  cutoff DATE NOT NULL,
+  last_result_id INT NOT NULL,
+  last_result_date VARCHAR(10) NOT NULL,
+  result_count INT NOT NULL,
+  windows INT NOT NULL,
+  data MEDIUMBLOB NOT NULL,
+  updated_at DATETIME NOT NULL
+);
@@ -3,27 +3,246 @@ package model
 import "time"
 
 type Analysis struct {
-	BestNumbers       []int     `json:"best_numbers"`
-	BestScores        []float64 `json:"best_scores"`
-	TotalProcessed    int       `json:"total_processed"`
-	GroupDaysAnalyzed int       `json:"days_analyzed"`
-	ExecutionTime     string    `json:"execution_time"`
-	Timestamp         time.Time `json:"timestamp"`
-	UnplayedCount     int       `json:"unplayed_count"`
+	ID                int                 `json:"id,omitempty"` // fila de la tabla analysis, no va en el JSON guardado
+	BestNumbers       []int               `json:"best_numbers"`
+	BestScores        []float64           `json:"best_scores"`
+	BestSigns         []string            `json:"best_signs"`
+	BestSignScores    []float64           `json:"best_sign_scores"`
+	BestCombinations  []NumberSign        `json:"best_combinations"`
+	TotalProcessed    int                 `json:"total_processed"`
+	GroupDaysAnalyzed int                 `json:"days_analyzed"`
+	ExecutionTime     string              `json:"-"`         // no se serializa: el análisis guardado es reproducible
+	Timestamp         time.Time           `json:"timestamp"` // día en que se anclan las ventanas (as-of)
+	UnplayedCount     int                 `json:"unplayed_count"`
+	Params            AnalysisParams      `json:"params"`
+	Ensemble          *EnsembleReport     `json:"ensemble,omitempty"`
+	Baseline          *BaselineComparison `json:"baseline,omitempty"`
+	Mode              string              `json:"mode"`
+	Rankings          []Ranking           `json:"rankings,omitempty"`
+	Stability         []RankStability     `json:"stability,omitempty"`
+	Key               AnalysisKey         `json:"key"`
+}
+
+// DataFingerprint identifica los sorteos con los que se calculó un análisis: si llega uno nuevo
+// cambian el último id y la cantidad. LastDate es el último día con sorteo; la columna date es DATE
+// pero se sigue formateando dd/mm/yyyy, como antes de la migración 3, así las claves guardadas en
+// analysis y frequency_state siguen sirviendo.
+type DataFingerprint struct {
+	LastID   int    `json:"last_id"`
+	LastDate string `json:"last_date"`
+	Count    int    `json:"count"`
+}
+
+// AnalysisKey identifica un análisis guardado; sólo se reutiliza si coinciden todos los campos.
+type AnalysisKey struct {
+	Strategy    string          `json:"strategy"`
+	ParamsHash  string          `json:"params_hash"`
+	AsOf        string          `json:"as_of"`
+	Fingerprint DataFingerprint `json:"fingerprint"`
+}
+
+// AnalysisRecord es una fila de la tabla analysis; Data es el JSON del análisis y no se lista.
+type AnalysisRecord struct {
+	ID        int         `json:"id"`
+	Lottery   int         `json:"lottery"`
+	Key       AnalysisKey `json:"key"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      []byte      `json:"-"`
+}
+
+// AnalysisFrequencies son tablas de frecuencias guardadas con un análisis. El índice de cada valor es
+// el número que forman los dígitos de la tabla (d1*10+d2 en las de dos cifras, d1*100+d2*10+d3 en
+// las de tres) y en las de signo×dígito es signo*10+dígito. Las de signo sólo tienen los 12 signos de
+// Signs (0-11): los desconocidos se cuentan por ventana pero no entran en las frecuencias.
+type AnalysisFrequencies struct {
+	AnalysisID int                  `json:"analysis_id"`
+	Group      string               `json:"group"`
+	Tables     map[string][]float64 `json:"tables"`
+}
+
+// AnalysisFilter limita y pagina el listado de análisis guardados; las fechas son del día del
+// análisis (o de creación en los guardados antes de tener as_of).
+type AnalysisFilter struct {
+	Strategy string
+	Start    time.Time
+	End      time.Time
+	Limit    int
+	Offset   int
+}
+
+// AnalysisPage es una página del listado de análisis guardados, del más nuevo al más viejo.
+type AnalysisPage struct {
+	Analyses []*AnalysisRecord `json:"analyses"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+// RankingDiff compara el top-N de un modo entre dos análisis guardados.
+type RankingDiff struct {
+	From    int          `json:"from"`
+	To      int          `json:"to"`
+	Mode    string       `json:"mode"`
+	TopN    int          `json:"top_n"`
+	Kept    int          `json:"kept"`
+	Entered []RankChange `json:"entered"`
+	Left    []RankChange `json:"left"`
+	Moved   []RankChange `json:"moved"` // los que siguen en el top-N y cambiaron de puesto
+}
+
+// RankChange es el cambio de un número entre dos rankings; el puesto 0 indica que no aparece en el
+// ranking guardado y entonces no hay score.
+type RankChange struct {
+	Number     int      `json:"number"`
+	FromRank   int      `json:"from_rank"`
+	ToRank     int      `json:"to_rank"`
+	Movement   int      `json:"movement"` // positivo si sube
+	FromScore  *float64 `json:"from_score,omitempty"`
+	ToScore    *float64 `json:"to_score,omitempty"`
+	ScoreDelta *float64 `json:"score_delta,omitempty"`
+}
+
+// Ranking es la selección de mejores números, signos y combinaciones para un modo (cold, hot,
+// distance); todos salen de las mismas frecuencias.
+type Ranking struct {
+	Mode         string              `json:"mode"`
+	Numbers      []int               `json:"numbers"`
+	Scores       []float64           `json:"scores"`
+	Signs        []string            `json:"signs"`
+	SignScores   []float64           `json:"sign_scores"`
+	Combinations []NumberSign        `json:"combinations"`
+	Baseline     *BaselineComparison `json:"baseline,omitempty"`
+	Ensemble     *EnsembleReport     `json:"ensemble,omitempty"`  // pesos del modo y contribuciones
+	Stability    []RankStability     `json:"stability,omitempty"` // mismo orden que Numbers
+}
+
+// RankStability dice qué tan seguido queda un número entre los mejores al remuestrear el histórico
+// (bootstrap) y cómo se reparte su posición; los rangos empiezan en 1.
+type RankStability struct {
+	Number   int     `json:"number"`
+	Top10    float64 `json:"top_10"`
+	Top50    float64 `json:"top_50"`
+	Top100   float64 `json:"top_100"`
+	RankP05  int     `json:"rank_p05"`
+	RankP25  int     `json:"rank_p25"`
+	RankP50  int     `json:"rank_p50"`
+	RankP75  int     `json:"rank_p75"`
+	RankP95  int     `json:"rank_p95"`
+	MeanRank float64 `json:"mean_rank"`
+}
+
+// NumberSign es una combinación número+signo propuesta para el próximo sorteo.
+type NumberSign struct {
+	Number int     `json:"number"`
+	Sign   string  `json:"sign"`
+	Score  float64 `json:"score"`
+}
+
+// BestNumbers es la vista recortada de un análisis que se sirve por la API.
+type BestNumbers struct {
+	Strategy     string              `json:"strategy"`
+	Mode         string              `json:"mode"`
+	Numbers      []int               `json:"numbers"`
+	Scores       []float64           `json:"scores"`
+	Signs        []string            `json:"signs"`
+	SignScores   []float64           `json:"sign_scores"`
+	Combinations []NumberSign        `json:"combinations"`
+	Ensemble     *EnsembleReport     `json:"ensemble,omitempty"`
+	Baseline     *BaselineComparison `json:"baseline,omitempty"`
+	Entries      []RankedNumber      `json:"entries"`
+}
+
+// RankedNumber es un puesto del ranking con su score y, si se pidió bootstrap, su estabilidad.
+type RankedNumber struct {
+	Rank      int            `json:"rank"`
+	Number    int            `json:"number"`
+	Score     float64        `json:"score"`
+	Stability *RankStability `json:"stability,omitempty"`
 }
 
 type AnalysisParams struct {
-	MaxIterations int `json:"max_iterations"`
-	TopNumbers    int `json:"top_numbers"`
+	MaxIterations int    `json:"max_iterations"`
+	TopNumbers    int    `json:"top_numbers"`
+	Strategy      string `json:"strategy"`
+	Mode          string `json:"mode"`
+	Normalization string `json:"normalization,omitempty"`
+	FitDraws      int    `json:"fit_draws,omitempty"`
+	Simulations   int    `json:"simulations"`
+	Seed          int64  `json:"seed"`
+	BaselineDraws int    `json:"baseline_draws"`
+
+	// Clases de patrón separadas por coma para filtrar los mejores números
+	Patterns        string `json:"patterns,omitempty"`
+	ExcludePatterns string `json:"exclude_patterns,omitempty"`
+
+	// Perfil de forma preferido, p. ej. "sum:10-20,even:2,high:1-3", y si filtra o sólo reordena
+	Shape     string `json:"shape,omitempty"`
+	ShapeMode string `json:"shape_mode,omitempty"`
+
+	// Día (yyyy-mm-dd) en que se anclan las ventanas y último día de sorteos que se tiene en cuenta;
+	// con los mismos parámetros el análisis siempre da el mismo resultado
+	AsOf   string `json:"as_of"`
+	Cutoff string `json:"cutoff"`
+
+	// Remuestreos del histórico para medir la estabilidad del ranking, 0 para no calcularla
+	Bootstrap int `json:"bootstrap,omitempty"`
+}
+
+// BaselineComparison compara con los de selecciones aleatorias del mismo tamaño (Monte Carlo) los
+// aciertos en los últimos Draws sorteos del ranking armado sin verlos, con los sorteos anteriores a
+// HoldoutFrom.
+type BaselineComparison struct {
+	Simulations      int             `json:"simulations"`
+	Seed             int64           `json:"seed"`
+	Draws            int             `json:"draws"`
+	HoldoutFrom      time.Time       `json:"holdout_from"`
+	TopN             int             `json:"top_n"`
+	Levels           []BaselineLevel `json:"levels"`
+	BetterThanRandom bool            `json:"better_than_random"`
+}
+
+// BaselineLevel resume un nivel de acierto (exacto, últimas tres, últimas dos cifras).
+type BaselineLevel struct {
+	Match        string  `json:"match"`
+	Hits         int     `json:"hits"`
+	RandomMean   float64 `json:"random_mean"`
+	RandomStd    float64 `json:"random_std"`
+	PValue       float64 `json:"p_value"`
+	Distribution []int   `json:"distribution"` // simulaciones con 0, 1, 2... aciertos
+}
+
+// EnsembleReport muestra los pesos ajustados del ensemble para un modo y cuánto aporta cada miembro a
+// cada número.
+type EnsembleReport struct {
+	Mode          string                 `json:"mode,omitempty"`
+	Normalization string                 `json:"normalization"`
+	Weights       map[string]float64     `json:"weights"`
+	FitDraws      int                    `json:"fit_draws"`
+	FitScore      float64                `json:"fit_score"` // percentil medio del número ganador en el orden del modo
+	Contributions []EnsembleContribution `json:"contributions"`
+}
+
+type EnsembleContribution struct {
+	Number  int                  `json:"number"`
+	Total   float64              `json:"total"`
+	Members []MemberContribution `json:"members"`
+}
+
+type MemberContribution struct {
+	Strategy     string  `json:"strategy"`
+	Weight       float64 `json:"weight"`
+	Normalized   float64 `json:"normalized"`
+	Contribution float64 `json:"contribution"`
 }
 
 type Statistics struct {
-	TotalResults   int                  `json:"total_results"`
-	DateRange      DateRange            `json:"date_range"`
-	DigitFreq      *DigitFrequency      `json:"digit_frequency"`
-	TwoDigitFreq   *TwoDigitFrequency   `json:"two_digit_frequency"`
-	ThreeDigitFreq *ThreeDigitFrequency `json:"three_digit_frequency"`
-	FourDigitFreq  *FourDigitFrequency  `json:"four_digit_frequency"`
+	TotalResults   int                   `json:"total_results"`
+	DateRange      DateRange             `json:"date_range"`
+	DigitFreq      *DigitFrequency       `json:"digit_frequency"`
+	TwoDigitFreq   *TwoDigitFrequency    `json:"two_digit_frequency"`
+	ThreeDigitFreq *ThreeDigitFrequency  `json:"three_digit_frequency"`
+	FourDigitFreq  *FourDigitFrequency   `json:"four_digit_frequency"`
+	Repetition     *RepetitionStatistics `json:"repetition"`
 }
 
 type DateRange struct {
@@ -31,9 +250,205 @@ type DateRange struct {
 	EndDate   time.Time `json:"end_date"`
 }
 
+// RepetitionStatistics guarda el intervalo medio, en sorteos, entre apariciones consecutivas de cada
+// valor (0 si salió menos de dos veces). Los pares y tríos siguen el orden de TwoDigitFrequency y
+// ThreeDigitFrequency.
 type RepetitionStatistics struct {
 	DigitRep      [][]float64 `json:"digit_repetition"`
 	TwoDigitRep   [][]float64 `json:"two_digit_repetition"`
 	ThreeDigitRep [][]float64 `json:"three_digit_repetition"`
 	FourDigitRep  []float64   `json:"four_digit_repetition"`
 }
+
+// PatternReport compara la frecuencia histórica de cada clase de patrón con la teórica.
+type PatternReport struct {
+	TotalDraws int           `json:"total_draws"`
+	Classes    []PatternStat `json:"classes"`
+}
+
+type PatternStat struct {
+	Class            string         `json:"class"`
+	Hits             int            `json:"hits"`
+	ObservedShare    float64        `json:"observed_share"`
+	TheoreticalShare float64        `json:"theoretical_share"`
+	Ratio            float64        `json:"ratio"`
+	LastSeen         *time.Time     `json:"last_seen"`
+	GapDraws         int            `json:"gap_draws"` // sorteos desde la última aparición, -1 si nunca salió
+	Trend            []PatternTrend `json:"trend"`
+}
+
+type PatternTrend struct {
+	Year  int     `json:"year"`
+	Draws int     `json:"draws"`
+	Hits  int     `json:"hits"`
+	Share float64 `json:"share"`
+}
+
+// ShapeReport describe la forma agregada de los sorteos: suma de dígitos (0-36), cantidad de dígitos
+// pares y cantidad de dígitos altos (5-9), frente a su distribución teórica exacta.
+type ShapeReport struct {
+	TotalDraws int               `json:"total_draws"`
+	Sum        ShapeDistribution `json:"sum"`
+	Even       ShapeDistribution `json:"even"`
+	High       ShapeDistribution `json:"high"`
+	Windows    []ShapeWindow     `json:"windows"`
+}
+
+type ShapeDistribution struct {
+	Observed         []int     `json:"observed"`
+	ObservedShare    []float64 `json:"observed_share"`
+	TheoreticalShare []float64 `json:"theoretical_share"`
+	ChiSquare        float64   `json:"chi_square"`
+	DegreesOfFreedom int       `json:"degrees_of_freedom"`
+	PValue           float64   `json:"p_value"`
+}
+
+// ShapeWindow es la forma de los sorteos posteriores a Since (mismas ventanas que las frecuencias).
+type ShapeWindow struct {
+	Since     time.Time `json:"since"`
+	Draws     int       `json:"draws"`
+	SumShare  []float64 `json:"sum_share"`
+	EvenShare []float64 `json:"even_share"`
+	HighShare []float64 `json:"high_share"`
+}
+
+// CorrelationReport relaciona los sorteos de dos loterías que cayeron el mismo día.
+type CorrelationReport struct {
+	LotteryA     int                   `json:"lottery_a"`
+	LotteryB     int                   `json:"lottery_b"`
+	DateRange    DateRange             `json:"date_range"`
+	AlignedDraws int                   `json:"aligned_draws"`
+	Positions    []PositionCorrelation `json:"positions"`
+	SameNumber   MatchTest             `json:"same_number"`
+	SameSign     MatchTest             `json:"same_sign"`
+}
+
+// PositionCorrelation cruza el dígito de una posición de A con el de una posición de B; las
+// posiciones distintas miden coincidencias desplazadas.
+type PositionCorrelation struct {
+	PositionA        string    `json:"position_a"`
+	PositionB        string    `json:"position_b"`
+	CoOccurrence     [][]int   `json:"co_occurrence"` // [dígito de A][dígito de B]
+	ChiSquare        float64   `json:"chi_square"`
+	DegreesOfFreedom int       `json:"degrees_of_freedom"`
+	PValue           float64   `json:"p_value"`
+	AdjustedPValue   float64   `json:"adjusted_p_value"` // Bonferroni sobre todas las parejas de posiciones
+	Pearson          float64   `json:"pearson"`
+	PearsonPValue    float64   `json:"pearson_p_value"`
+	SameDigit        MatchTest `json:"same_digit"`
+}
+
+// MatchTest es una prueba binomial exacta de las coincidencias frente a las esperadas por azar.
+type MatchTest struct {
+	Trials        int     `json:"trials"`
+	Matches       int     `json:"matches"`
+	ObservedShare float64 `json:"observed_share"`
+	ExpectedShare float64 `json:"expected_share"`
+	PValue        float64 `json:"p_value"`
+}
+
+// SeasonalityReport reparte los sorteos por día de la semana y por mes para ver si los dígitos o
+// los signos dependen del calendario.
+type SeasonalityReport struct {
+	TotalDraws int               `json:"total_draws"`
+	DateRange  DateRange         `json:"date_range"`
+	Weekday    SeasonalBreakdown `json:"weekday"`
+	Month      SeasonalBreakdown `json:"month"`
+}
+
+// SeasonalBreakdown trae la prueba de independencia grupo x valor de cada posición y del signo, y
+// el detalle de cada grupo frente a la distribución global.
+type SeasonalBreakdown struct {
+	Positions []SeasonalTest  `json:"positions"`
+	Sign      SeasonalTest    `json:"sign"`
+	Groups    []SeasonalGroup `json:"groups"`
+}
+
+type SeasonalTest struct {
+	Position         string  `json:"position"`
+	ChiSquare        float64 `json:"chi_square"`
+	DegreesOfFreedom int     `json:"degrees_of_freedom"`
+	PValue           float64 `json:"p_value"`
+}
+
+// SeasonalGroup son los sorteos de un día de la semana o de un mes.
+type SeasonalGroup struct {
+	Label      string         `json:"label"`
+	Draws      int            `json:"draws"`
+	Digits     [][]int        `json:"digits"` // [posición][dígito]
+	DigitTests []SeasonalTest `json:"digit_tests"`
+	Signs      []int          `json:"signs"` // en el orden de Signs
+	SignTest   SeasonalTest   `json:"sign_test"`
+}
+
+// DependencyParams elige los sorteos y la prueba de permutaciones del análisis de dependencias.
+type DependencyParams struct {
+	Draws        int   `json:"draws"` // últimos sorteos del rango, 0 para todos
+	Permutations int   `json:"permutations"`
+	Seed         int64 `json:"seed"`
+}
+
+// DependencyReport mide cuánta información comparten las posiciones de los sorteos, para saber qué
+// términos de TwoDigitFrequency y ThreeDigitFrequency aportan algo.
+type DependencyReport struct {
+	TotalDraws   int                     `json:"total_draws"`
+	DateRange    DateRange               `json:"date_range"`
+	Params       DependencyParams        `json:"params"`
+	Pairs        []PairDependency        `json:"pairs"`
+	Conditionals []ConditionalDependency `json:"conditionals"`
+}
+
+// PairDependency es la dependencia entre dos posiciones; la información mutua está en bits y
+// PermutationMean es su valor medio al romper la relación, es decir, el sesgo esperado por azar.
+type PairDependency struct {
+	Positions         string  `json:"positions"` // mismo nombre que en TwoDigitFrequency
+	MutualInformation float64 `json:"mutual_information"`
+	CramersV          float64 `json:"cramers_v"`
+	ChiSquare         float64 `json:"chi_square"`
+	DegreesOfFreedom  int     `json:"degrees_of_freedom"`
+	PValue            float64 `json:"p_value"`
+	PermutationMean   float64 `json:"permutation_mean"`
+	PermutationPValue float64 `json:"permutation_p_value"`
+}
+
+// ConditionalDependency es la información mutua de una pareja de posiciones conocida la tercera
+// posición del trío.
+type ConditionalDependency struct {
+	Triple            string  `json:"triple"` // p. ej. first_second_fourth
+	Pair              string  `json:"pair"`
+	Given             string  `json:"given"`
+	MutualInformation float64 `json:"mutual_information"`
+	PermutationMean   float64 `json:"permutation_mean"`
+	PermutationPValue float64 `json:"permutation_p_value"`
+}
+
+// WheelParams configura el optimizador de cobertura de terminaciones.
+type WheelParams struct {
+	Budget      int     `json:"budget"`      // cantidad de números a jugar
+	Temperature float64 `json:"temperature"` // qué tanto se separa la probabilidad modelada de la uniforme
+	ThreeWeight float64 `json:"three_weight"`
+	TwoWeight   float64 `json:"two_weight"`
+}
+
+// WheelResult es el conjunto de números elegido para cubrir terminaciones de tres y dos cifras, con
+// su cobertura frente a jugar directamente los primeros números del ranking.
+type WheelResult struct {
+	Strategy      string        `json:"strategy"`
+	Mode          string        `json:"mode"`
+	Params        WheelParams   `json:"params"`
+	Numbers       []int         `json:"numbers"`
+	Probabilities []float64     `json:"probabilities"` // probabilidad modelada de cada número
+	Coverage      WheelCoverage `json:"coverage"`
+	TopCoverage   WheelCoverage `json:"top_coverage"` // los primeros Budget números del ranking
+	Swaps         int           `json:"swaps"`        // intercambios de la búsqueda local
+}
+
+// WheelCoverage son las probabilidades modeladas de acertar con un conjunto de números.
+type WheelCoverage struct {
+	Exact             float64 `json:"exact"`
+	LastThree         float64 `json:"last_three"`
+	LastTwo           float64 `json:"last_two"`
+	DistinctLastThree int     `json:"distinct_last_three"`
+	DistinctLastTwo   int     `json:"distinct_last_two"`
+	Objective         float64 `json:"objective"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Job es un análisis que corre en segundo plano; se consulta por su id hasta que termina.
+type Job struct {
+	ID          string         `json:"id"`
+	State       string         `json:"state"`
+	Params      AnalysisParams `json:"params"`
+	Progress    JobProgress    `json:"progress"`
+	SubmittedAt time.Time      `json:"submitted_at"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+	Duration    string         `json:"duration,omitempty"` // desde que empezó hasta que terminó, o hasta ahora
+	Error       string         `json:"error,omitempty"`
+	Analysis    *Analysis      `json:"analysis,omitempty"`
+}
+
+// JobProgress es el avance de un análisis: paso actual, fechas scrapeadas y ventanas de frecuencias.
+type JobProgress struct {
+	Step         string `json:"step"`
+	ScrapedDates int    `json:"scraped_dates"`
+	Windows      int    `json:"windows"`
+	TotalWindows int    `json:"total_windows"`
+}
@@ -0,0 +1,42 @@
+package model
+
+// PrizeTable son los pagos por unidad apostada de una lotería, por tipo de apuesta. Combined son
+// los pagos de la apuesta combinada, que paga sólo el mayor nivel acertado.
+type PrizeTable struct {
+	Lottery  int                `json:"lottery"`
+	Prizes   map[string]float64 `json:"prizes"`
+	Combined map[string]float64 `json:"combined"`
+}
+
+// Ticket es una apuesta: número, tipo de apuesta, signo si la apuesta lo lleva y valor apostado.
+type Ticket struct {
+	Number int     `json:"number"`
+	Sign   string  `json:"sign,omitempty"`
+	Bet    string  `json:"bet"`
+	Stake  float64 `json:"stake"`
+}
+
+// TicketValue es el retorno esperado de una apuesta con la probabilidad modelada y con la uniforme.
+type TicketValue struct {
+	Ticket
+	WinProbability        float64 `json:"win_probability"` // de cobrar algún premio
+	UniformWinProbability float64 `json:"uniform_win_probability"`
+	ExpectedReturn        float64 `json:"expected_return"` // por unidad apostada
+	UniformReturn         float64 `json:"uniform_return"`
+	ExpectedPayout        float64 `json:"expected_payout"` // ExpectedReturn * Stake
+}
+
+// ExpectedValueReport suma el valor esperado de un conjunto de apuestas.
+type ExpectedValueReport struct {
+	Lottery        int           `json:"lottery"`
+	Strategy       string        `json:"strategy"`
+	Mode           string        `json:"mode"`
+	Temperature    float64       `json:"temperature"`
+	PrizeTable     PrizeTable    `json:"prize_table"`
+	Tickets        []TicketValue `json:"tickets"`
+	Stake          float64       `json:"stake"`
+	ExpectedPayout float64       `json:"expected_payout"`
+	UniformPayout  float64       `json:"uniform_payout"`
+	ExpectedReturn float64       `json:"expected_return"` // por unidad apostada en total
+	UniformReturn  float64       `json:"uniform_return"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type DigitFrequency struct {
 	Position1 []float64 `json:"position_1"`
 	Position2 []float64 `json:"position_2"`
@@ -27,11 +29,82 @@ type FourDigitFrequency struct {
 	Complete []float64 `json:"complete"`
 }
 
+// SignFrequency guarda la frecuencia de cada signo y la frecuencia conjunta signo×dígito
+// por posición, indexada como signo*10+dígito.
+type SignFrequency struct {
+	Signs     []float64 `json:"signs"`
+	Position1 []float64 `json:"position_1"`
+	Position2 []float64 `json:"position_2"`
+	Position3 []float64 `json:"position_3"`
+	Position4 []float64 `json:"position_4"`
+}
+
 type FrequencyData struct {
 	DigitFreq      DigitFrequency      `json:"digit_frequencies"`
 	TwoDigitFreq   TwoDigitFrequency   `json:"two_digit_frequencies"`
 	ThreeDigitFreq ThreeDigitFrequency `json:"three_digit_frequencies"`
 	FourDigitFreq  FourDigitFrequency  `json:"four_digit_frequencies"`
+	SignFreq       SignFrequency       `json:"sign_frequencies"`
+}
+
+// FrequencyState son los conteos acumulados de cada ventana fibonacci guardados por lotería, para
+// actualizar las frecuencias con los sorteos nuevos sin recalcularlas. Data va comprimido.
+type FrequencyState struct {
+	AsOf        time.Time       `json:"as_of"`
+	Until       time.Time       `json:"until"`
+	Fingerprint DataFingerprint `json:"fingerprint"`
+	Windows     int             `json:"windows"`
+	Data        []byte          `json:"-"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// FrequencyCheck compara las frecuencias incrementales con el recálculo completo por SQL.
+type FrequencyCheck struct {
+	AsOf        string                `json:"as_of"`
+	Cutoff      string                `json:"cutoff"`
+	Windows     int                   `json:"windows"`
+	Incremental string                `json:"incremental"` // advanced, rebuilt o loaded
+	Tables      []FrequencyTableCheck `json:"tables"`
+	Consistent  bool                  `json:"consistent"`
+}
+
+// FrequencyTableCheck es el resultado de la comparación de una tabla de frecuencias.
+type FrequencyTableCheck struct {
+	Table      string  `json:"table"`
+	Cells      int     `json:"cells"`
+	Mismatches int     `json:"mismatches"`
+	MaxDiff    float64 `json:"max_diff"`
+}
+
+// NumberScore explica el score de un número con una estrategia y su puesto en el orden del modo entre
+// los Total números que pasan el filtro; Rank es 0 si el filtro lo descarta.
+type NumberScore struct {
+	Number     int              `json:"number"`
+	Strategy   string           `json:"strategy"`
+	Mode       string           `json:"mode"`
+	AsOf       string           `json:"as_of"`
+	Score      float64          `json:"score"`
+	Rank       int              `json:"rank"`
+	Total      int              `json:"total"`
+	Components []ScoreComponent `json:"components"`
+}
+
+// ScoreComponent es uno de los 15 sumandos del score (4 dígitos, 6 pares, 4 tríos y el número completo).
+type ScoreComponent struct {
+	Name    string               `json:"name"`
+	Kind    string               `json:"kind"`
+	Key     string               `json:"key"`
+	Count   int                  `json:"count"`
+	Score   float64              `json:"score"`
+	Windows []WindowContribution `json:"windows"`
+}
+
+// WindowContribution es lo que aporta una ventana de fechas a un componente.
+type WindowContribution struct {
+	Since        time.Time `json:"since"`
+	Count        int       `json:"count"`
+	Total        int       `json:"total"`
+	Contribution float64   `json:"contribution"`
 }
 
 type DatabaseStats struct {
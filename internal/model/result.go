@@ -1,14 +1,31 @@
 package model
 
+import "time"
+
 type Result struct {
-	ID      int    `json:"id" db:"id"`
-	Version int    `json:"version" db:"version"`
-	Date    string `json:"date" db:"date"`
-	First   int    `json:"first" db:"first"`
-	Second  int    `json:"second" db:"second"`
-	Third   int    `json:"third" db:"third"`
-	Fourth  int    `json:"fourth" db:"fourth"`
-	Sign    string `json:"sign" db:"sign"`
+	ID      int       `json:"id" db:"id"`
+	Lottery int       `json:"lottery" db:"lottery"`
+	Version int       `json:"version" db:"version"`
+	Date    time.Time `json:"date" db:"date"` // día del sorteo, columna DATE
+	First   int       `json:"first" db:"first"`
+	Second  int       `json:"second" db:"second"`
+	Third   int       `json:"third" db:"third"`
+	Fourth  int       `json:"fourth" db:"fourth"`
+	Sign    string    `json:"sign" db:"sign"`
+}
+
+// Signs son las letras con las que se guardan los signos zodiacales (A=acuario ... L=capricornio).
+// La "Z" representa un signo desconocido y no participa en el análisis.
+var Signs = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
+
+// SignIndex devuelve la posición del signo dentro de Signs o -1 si no es válido.
+func SignIndex(sign string) int {
+	for i, s := range Signs {
+		if s == sign {
+			return i
+		}
+	}
+	return -1
 }
 
 type DigitCount struct {
@@ -33,3 +50,14 @@ type FourDigitCount struct {
 	Number int `json:"number"`
 	Count  int `json:"count"`
 }
+
+type SignCount struct {
+	Sign  string `json:"sign"`
+	Count int    `json:"count"`
+}
+
+type SignDigitCount struct {
+	Sign  string `json:"sign"`
+	Digit int    `json:"digit"`
+	Count int    `json:"count"`
+}
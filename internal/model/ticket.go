@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// PlayedTicket es una apuesta comprada: se registra pendiente y se liquida cuando se guarda el
+// resultado de su lotería y fecha.
+type PlayedTicket struct {
+	ID       int       `json:"id" db:"id"`
+	Lottery  int       `json:"lottery" db:"lottery"`
+	User     string    `json:"user" db:"username"`
+	Strategy string    `json:"strategy,omitempty" db:"strategy"`
+	Date     time.Time `json:"date" db:"date"` // día del sorteo, igual que Result.Date
+	Ticket
+	Status    string     `json:"status" db:"status"`
+	Payout    float64    `json:"payout" db:"payout"`
+	ResultID  int        `json:"result_id,omitempty" db:"result_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	SettledAt *time.Time `json:"settled_at,omitempty" db:"settled_at"`
+}
+
+// TicketFilter limita las apuestas consultadas; los campos vacíos no filtran.
+type TicketFilter struct {
+	Lottery  int
+	User     string
+	Strategy string
+	Status   string
+	Start    time.Time
+	End      time.Time
+}
+
+// LedgerSummary es el resultado de un grupo de apuestas; las pendientes cuentan en Stake pero no
+// en Net ni en ROI.
+type LedgerSummary struct {
+	Group   string  `json:"group"`
+	Tickets int     `json:"tickets"`
+	Pending int     `json:"pending"`
+	Won     int     `json:"won"`
+	Lost    int     `json:"lost"`
+	Stake   float64 `json:"stake"`
+	Settled float64 `json:"settled_stake"`
+	Payout  float64 `json:"payout"`
+	Net     float64 `json:"net"` // Payout - Settled
+	ROI     float64 `json:"roi"` // Net / Settled
+}
+
+// LedgerReport agrupa las apuestas por usuario o por estrategia.
+type LedgerReport struct {
+	By     string          `json:"by"`
+	Total  LedgerSummary   `json:"total"`
+	Groups []LedgerSummary `json:"groups"`
+}
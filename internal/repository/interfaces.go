@@ -9,25 +9,45 @@ import (
 
 // ResultRepository define las operaciones de acceso a datos para Result
 type ResultRepository interface {
+	Lottery() int
+	WithLottery(lottery int) ResultRepository
 	Create(ctx context.Context, result *model.Result) error
 	LastResult(ctx context.Context) (*model.Result, error)
-	OneDigit(ctx context.Context, cal time.Time, position string) ([]*model.DigitCount, error)
-	TwoDigit(ctx context.Context, cal time.Time, position1 string, position2 string) ([]*model.TwoDigitCount, error)
-	ThreeDigit(ctx context.Context, cal time.Time, position1 string, position2 string, position3 string) ([]*model.ThreeDigitCount, error)
-	FourthDigit(ctx context.Context, cal time.Time) ([]*model.FourDigitCount, error)
-	SaveAnalysis(ctx context.Context, b *[]byte) error
-	ShouldAnalyzeDate(ctx context.Context, date time.Time) (bool, error)
-	LastAnalysis(ctx context.Context) ([]byte, error)
+	OneDigit(ctx context.Context, cal, until time.Time, position string) ([]*model.DigitCount, error)
+	TwoDigit(ctx context.Context, cal, until time.Time, position1 string, position2 string) ([]*model.TwoDigitCount, error)
+	ThreeDigit(ctx context.Context, cal, until time.Time, position1 string, position2 string, position3 string) ([]*model.ThreeDigitCount, error)
+	FourthDigit(ctx context.Context, cal, until time.Time) ([]*model.FourDigitCount, error)
+	Sign(ctx context.Context, cal, until time.Time) ([]*model.SignCount, error)
+	SignDigit(ctx context.Context, cal, until time.Time, position string) ([]*model.SignDigitCount, error)
+	SaveAnalysis(ctx context.Context, key model.AnalysisKey, b *[]byte, frequencies []byte) (int, error)
+	AnalysisFrequencies(ctx context.Context, id int) (*[]byte, error)
+	LastAnalysis(ctx context.Context, key model.AnalysisKey) (*model.AnalysisRecord, error)
+	AnalysisByID(ctx context.Context, id int) (*model.AnalysisRecord, error)
+	Analyses(ctx context.Context, filter model.AnalysisFilter) ([]*model.AnalysisRecord, int, error)
+	Fingerprint(ctx context.Context, until time.Time) (*model.DataFingerprint, error)
+	FrequencyState(ctx context.Context) (*model.FrequencyState, error)
+	SaveFrequencyState(ctx context.Context, state *model.FrequencyState) error
+	Lock(ctx context.Context, name string, timeout time.Duration) (func(), error)
 	CreateBatch(ctx context.Context, results []*model.Result) error
 	ID(ctx context.Context, id int) (*model.Result, error)
-	Date(ctx context.Context, date string) ([]*model.Result, error)
+	Date(ctx context.Context, date time.Time) ([]*model.Result, error)
 	LastNResults(ctx context.Context, limit int) ([]*model.Result, error)
 	BetweenDates(ctx context.Context, startDate, endDate time.Time) ([]*model.Result, error)
 	AfterDate(ctx context.Context, date time.Time) ([]*model.Result, error)
 	Update(ctx context.Context, result *model.Result) error
 	Delete(ctx context.Context, id int) error
-	Exists(ctx context.Context, date string) (bool, error)
+	Exists(ctx context.Context, date time.Time) (bool, error)
 	Count(ctx context.Context) (int, error)
 	CountBetweenDates(ctx context.Context, startDate, endDate time.Time) (int, error)
 	AllPlayedNumbers(ctx context.Context) ([]string, error)
 }
+
+// TicketRepository define las operaciones de acceso a datos de las apuestas registradas
+type TicketRepository interface {
+	Create(ctx context.Context, ticket *model.PlayedTicket) error
+	ID(ctx context.Context, id int) (*model.PlayedTicket, error)
+	Pending(ctx context.Context, lottery int, date time.Time) ([]*model.PlayedTicket, error)
+	Settleable(ctx context.Context, lottery int) ([]*model.PlayedTicket, error)
+	Settle(ctx context.Context, ticket *model.PlayedTicket) error
+	List(ctx context.Context, filter model.TicketFilter) ([]*model.PlayedTicket, error)
+}
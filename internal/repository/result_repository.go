@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"lottery-analyzer/internal/model"
@@ -11,31 +12,54 @@ import (
 )
 
 type resultRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	lottery int
 }
 
-func NewResultRepository(db *sql.DB) ResultRepository {
-	return &resultRepository{db: db}
+// NewResultRepository crea el repositorio de resultados de una lotería; todas las consultas quedan
+// limitadas a ella.
+func NewResultRepository(db *sql.DB, lottery int) ResultRepository {
+	return &resultRepository{db: db, lottery: lottery}
+}
+
+func (r *resultRepository) Lottery() int {
+	return r.lottery
+}
+
+// WithLottery devuelve el mismo repositorio limitado a otra lotería.
+func (r *resultRepository) WithLottery(lottery int) ResultRepository {
+	return &resultRepository{db: r.db, lottery: lottery}
 }
 
 func (r *resultRepository) Create(ctx context.Context, result *model.Result) error {
-	query := `INSERT INTO result (version, date, first, second, third, fourth, sign) 
-              VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO result (lottery, version, date, first, second, third, fourth, sign) 
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	res, err := r.db.ExecContext(ctx, query, r.lottery,
 		result.Version, result.Date, result.First, result.Second,
 		result.Third, result.Fourth, result.Sign)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// El ID y la lotería quedan en el resultado para liquidar las apuestas de ese sorteo
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	result.ID = int(id)
+	result.Lottery = r.lottery
+	return nil
 }
 
+// LastResult devuelve el sorteo más reciente por fecha, aunque se haya guardado antes que otros.
 func (r *resultRepository) LastResult(ctx context.Context) (*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result ORDER BY id DESC LIMIT 1`
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE lottery = ? ORDER BY date DESC, id DESC LIMIT 1`
 
 	var result model.Result
-	err := r.db.QueryRowContext(ctx, query).Scan(
-		&result.ID, &result.Version, &result.Date,
+	err := r.db.QueryRowContext(ctx, query, r.lottery).Scan(
+		&result.ID, &result.Lottery, &result.Version, &result.Date,
 		&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign)
 
 	if err == sql.ErrNoRows {
@@ -44,18 +68,18 @@ func (r *resultRepository) LastResult(ctx context.Context) (*model.Result, error
 	return &result, err
 }
 
-func (r *resultRepository) OneDigit(ctx context.Context, cal time.Time, position string) ([]*model.DigitCount, error) {
+func (r *resultRepository) OneDigit(ctx context.Context, cal, until time.Time, position string) ([]*model.DigitCount, error) {
 
 	escapedCol := utils.EscapeIdentifier(position) // Escapa para evitar SQL injection
 
 	query := fmt.Sprintf(`SELECT COUNT(%s) AS repetition, %s 
                           FROM result 
-                          WHERE STR_TO_DATE(date, ?) > ? 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
                           GROUP BY %s`,
 		escapedCol, escapedCol, escapedCol)
 
 	// Ejecuta la query con placeholders solo para valores
-	rows, err := r.db.QueryContext(ctx, query, "%d/%m/%Y", cal)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err) // Manejo de errores con wrapping
 	}
@@ -72,19 +96,19 @@ func (r *resultRepository) OneDigit(ctx context.Context, cal time.Time, position
 	return counts, rows.Err()
 }
 
-func (r *resultRepository) TwoDigit(ctx context.Context, cal time.Time, position1 string, position2 string) ([]*model.TwoDigitCount, error) {
+func (r *resultRepository) TwoDigit(ctx context.Context, cal, until time.Time, position1 string, position2 string) ([]*model.TwoDigitCount, error) {
 
 	escapedCol1 := utils.EscapeIdentifier(position1) // Escapa para evitar SQL injection
 	escapedCol2 := utils.EscapeIdentifier(position2) // Escapa para evitar SQL injection
 
 	query := fmt.Sprintf(`SELECT COUNT(%s) AS repetition, %s, %s 
                           FROM result 
-                          WHERE STR_TO_DATE(date, ?) > ? 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
                           GROUP BY %s, %s`,
 		escapedCol1, escapedCol1, escapedCol2, escapedCol1, escapedCol2)
 
 	// Ejecuta la query con placeholders solo para valores
-	rows, err := r.db.QueryContext(ctx, query, "%d/%m/%Y", cal)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err) // Manejo de errores con wrapping
 	}
@@ -101,7 +125,7 @@ func (r *resultRepository) TwoDigit(ctx context.Context, cal time.Time, position
 	return counts, rows.Err()
 }
 
-func (r *resultRepository) ThreeDigit(ctx context.Context, cal time.Time, position1 string, position2 string, position3 string) ([]*model.ThreeDigitCount, error) {
+func (r *resultRepository) ThreeDigit(ctx context.Context, cal, until time.Time, position1 string, position2 string, position3 string) ([]*model.ThreeDigitCount, error) {
 
 	escapedCol1 := utils.EscapeIdentifier(position1) // Escapa para evitar SQL injection
 	escapedCol2 := utils.EscapeIdentifier(position2) // Escapa para evitar SQL injection
@@ -109,12 +133,12 @@ func (r *resultRepository) ThreeDigit(ctx context.Context, cal time.Time, positi
 
 	query := fmt.Sprintf(`SELECT COUNT(%s) AS repetition, %s, %s, %s
                           FROM result 
-                          WHERE STR_TO_DATE(date, ?) > ? 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
                           GROUP BY %s, %s, %s`,
 		escapedCol1, escapedCol1, escapedCol2, escapedCol3, escapedCol1, escapedCol2, escapedCol3)
 
 	// Ejecuta la query con placeholders solo para valores
-	rows, err := r.db.QueryContext(ctx, query, "%d/%m/%Y", cal)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err) // Manejo de errores con wrapping
 	}
@@ -131,13 +155,13 @@ func (r *resultRepository) ThreeDigit(ctx context.Context, cal time.Time, positi
 	return counts, rows.Err()
 }
 
-func (r *resultRepository) FourthDigit(ctx context.Context, cal time.Time) ([]*model.FourDigitCount, error) {
+func (r *resultRepository) FourthDigit(ctx context.Context, cal, until time.Time) ([]*model.FourDigitCount, error) {
 	query := `SELECT COUNT(first) AS repetition, first, second, third, fourth 
                           FROM result 
-                          WHERE STR_TO_DATE(date, ?) > ? 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
                           GROUP BY first, second, third, fourth`
 
-	rows, err := r.db.QueryContext(ctx, query, "%d/%m/%Y", cal)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
@@ -156,11 +180,61 @@ func (r *resultRepository) FourthDigit(ctx context.Context, cal time.Time) ([]*m
 	return counts, rows.Err()
 }
 
+func (r *resultRepository) Sign(ctx context.Context, cal, until time.Time) ([]*model.SignCount, error) {
+	query := `SELECT COUNT(sign) AS repetition, sign 
+                          FROM result 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
+                          GROUP BY sign`
+
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*model.SignCount
+	for rows.Next() {
+		var sc model.SignCount
+		if err := rows.Scan(&sc.Count, &sc.Sign); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &sc)
+	}
+	return counts, rows.Err()
+}
+
+func (r *resultRepository) SignDigit(ctx context.Context, cal, until time.Time, position string) ([]*model.SignDigitCount, error) {
+
+	escapedCol := utils.EscapeIdentifier(position) // Escapa para evitar SQL injection
+
+	query := fmt.Sprintf(`SELECT COUNT(sign) AS repetition, sign, %s 
+                          FROM result 
+                          WHERE lottery = ? AND date > ? AND date <= ? 
+                          GROUP BY sign, %s`,
+		escapedCol, escapedCol)
+
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, cal, until)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*model.SignDigitCount
+	for rows.Next() {
+		var sc model.SignDigitCount
+		if err := rows.Scan(&sc.Count, &sc.Sign, &sc.Digit); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &sc)
+	}
+	return counts, rows.Err()
+}
+
 func (r *resultRepository) AllPlayedNumbers(ctx context.Context) ([]string, error) {
 	query := `SELECT DISTINCT CONCAT(LPAD(first, 1, '0'), LPAD(second, 1, '0'), 
-              LPAD(third, 1, '0'), LPAD(fourth, 1, '0')) as number FROM result`
+              LPAD(third, 1, '0'), LPAD(fourth, 1, '0')) as number FROM result WHERE lottery = ?`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery)
 	if err != nil {
 		return nil, err
 	}
@@ -178,37 +252,205 @@ func (r *resultRepository) AllPlayedNumbers(ctx context.Context) ([]string, erro
 	return numbers, rows.Err()
 }
 
-func (r *resultRepository) SaveAnalysis(ctx context.Context, analysis *[]byte) error {
-	query := `INSERT INTO analysis (data, created_at) VALUES (?, ?)`
+// SaveAnalysis guarda el análisis con su clave: estrategia, hash de parámetros, día y huella de datos,
+// junto con sus tablas de frecuencias ya codificadas.
+// Devuelve el id de la fila.
+func (r *resultRepository) SaveAnalysis(ctx context.Context, key model.AnalysisKey, analysis *[]byte, frequencies []byte) (int, error) {
+	query := `INSERT INTO analysis (lottery, strategy, params_hash, as_of, last_result_id, last_result_date,
+              result_count, data, frequencies, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query, r.lottery, key.Strategy, key.ParamsHash, key.AsOf,
+		key.Fingerprint.LastID, key.Fingerprint.LastDate, key.Fingerprint.Count, analysis, frequencies, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	return int(id), err
+}
 
-	_, err := r.db.ExecContext(ctx, query, analysis, time.Now())
+const analysisColumns = `id, lottery, strategy, params_hash, as_of, last_result_id, last_result_date,
+              result_count, created_at`
 
-	return err
+// LastAnalysis devuelve el último análisis guardado con la misma clave o nil si no hay ninguno.
+func (r *resultRepository) LastAnalysis(ctx context.Context, key model.AnalysisKey) (*model.AnalysisRecord, error) {
+	query := `SELECT ` + analysisColumns + `, data FROM analysis 
+              WHERE lottery = ? AND strategy = ? AND params_hash = ? AND as_of = ? 
+              AND last_result_id = ? AND last_result_date = ? AND result_count = ? 
+              ORDER BY id DESC LIMIT 1`
 
+	record, err := scanAnalysis(r.db.QueryRowContext(ctx, query, r.lottery, key.Strategy, key.ParamsHash, key.AsOf,
+		key.Fingerprint.LastID, key.Fingerprint.LastDate, key.Fingerprint.Count), true)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return record, err
 }
 
-func (r *resultRepository) ShouldAnalyzeDate(ctx context.Context, date time.Time) (bool, error) {
-	query := `SELECT COUNT(*) FROM analysis WHERE DATE(created_at) = DATE(?)`
+// AnalysisByID devuelve un análisis guardado de la lotería o nil si no existe.
+func (r *resultRepository) AnalysisByID(ctx context.Context, id int) (*model.AnalysisRecord, error) {
+	query := `SELECT ` + analysisColumns + `, data FROM analysis WHERE lottery = ? AND id = ?`
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, date).Scan(&count)
+	record, err := scanAnalysis(r.db.QueryRowContext(ctx, query, r.lottery, id), true)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return record, err
+}
+
+// Analyses lista los análisis guardados sin su JSON, del más nuevo al más viejo, y devuelve también
+// el total que cumple el filtro.
+func (r *resultRepository) Analyses(ctx context.Context, filter model.AnalysisFilter) ([]*model.AnalysisRecord, int, error) {
+	conditions := []string{"lottery = ?"}
+	args := []interface{}{r.lottery}
+
+	if filter.Strategy != "" {
+		conditions = append(conditions, "strategy = ?")
+		args = append(args, filter.Strategy)
+	}
+	if !filter.Start.IsZero() {
+		conditions = append(conditions, "COALESCE(as_of, DATE(created_at)) >= ?")
+		args = append(args, filter.Start)
+	}
+	if !filter.End.IsZero() {
+		conditions = append(conditions, "COALESCE(as_of, DATE(created_at)) <= ?")
+		args = append(args, filter.End)
+	}
+	where := ` WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM analysis`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + analysisColumns + ` FROM analysis` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
-		return false, err
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var records []*model.AnalysisRecord
+	for rows.Next() {
+		record, err := scanAnalysis(rows, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+
+	return records, total, rows.Err()
+}
+
+func scanAnalysis(row rowScanner, withData bool) (*model.AnalysisRecord, error) {
+	var record model.AnalysisRecord
+	var asOf sql.NullTime
+
+	dest := []interface{}{&record.ID, &record.Lottery, &record.Key.Strategy, &record.Key.ParamsHash, &asOf,
+		&record.Key.Fingerprint.LastID, &record.Key.Fingerprint.LastDate, &record.Key.Fingerprint.Count,
+		&record.CreatedAt}
+	if withData {
+		dest = append(dest, &record.Data)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if asOf.Valid {
+		record.Key.AsOf = asOf.Time.Format(time.DateOnly)
+	}
+	return &record, nil
+}
+
+// AnalysisFrequencies devuelve las tablas de frecuencias codificadas de un análisis; nil si el
+// análisis no existe y un slice vacío si se guardó sin ellas.
+func (r *resultRepository) AnalysisFrequencies(ctx context.Context, id int) (*[]byte, error) {
+	query := `SELECT frequencies FROM analysis WHERE lottery = ? AND id = ?`
+
+	var frequencies []byte
+	err := r.db.QueryRowContext(ctx, query, r.lottery, id).Scan(&frequencies)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &frequencies, nil
+}
+
+// Fingerprint resume los sorteos guardados hasta until: último id, última fecha y cantidad.
+func (r *resultRepository) Fingerprint(ctx context.Context, until time.Time) (*model.DataFingerprint, error) {
+	query := `SELECT COUNT(*), COALESCE(MAX(id), 0),
+              COALESCE(DATE_FORMAT(MAX(date), '%d/%m/%Y'), '') 
+              FROM result WHERE lottery = ? AND date <= ?`
+
+	var fingerprint model.DataFingerprint
+	err := r.db.QueryRowContext(ctx, query, r.lottery, until).Scan(
+		&fingerprint.Count, &fingerprint.LastID, &fingerprint.LastDate)
+	if err != nil {
+		return nil, err
+	}
+	return &fingerprint, nil
+}
+
+// FrequencyState devuelve los conteos por ventana guardados de la lotería o nil si no hay.
+func (r *resultRepository) FrequencyState(ctx context.Context) (*model.FrequencyState, error) {
+	query := `SELECT as_of, cutoff, last_result_id, last_result_date, result_count, windows, data, updated_at 
+              FROM frequency_state WHERE lottery = ?`
+
+	var state model.FrequencyState
+	err := r.db.QueryRowContext(ctx, query, r.lottery).Scan(&state.AsOf, &state.Until,
+		&state.Fingerprint.LastID, &state.Fingerprint.LastDate, &state.Fingerprint.Count,
+		&state.Windows, &state.Data, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &state, nil
+}
 
-	// true si NO hay análisis para esa fecha, false si ya existe
-	return count == 0, nil
+// SaveFrequencyState reemplaza los conteos por ventana guardados de la lotería.
+func (r *resultRepository) SaveFrequencyState(ctx context.Context, state *model.FrequencyState) error {
+	query := `INSERT INTO frequency_state (lottery, as_of, cutoff, last_result_id, last_result_date, 
+              result_count, windows, data, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) 
+              ON DUPLICATE KEY UPDATE as_of = VALUES(as_of), cutoff = VALUES(cutoff), 
+              last_result_id = VALUES(last_result_id), last_result_date = VALUES(last_result_date), 
+              result_count = VALUES(result_count), windows = VALUES(windows), data = VALUES(data), 
+              updated_at = VALUES(updated_at)`
+
+	_, err := r.db.ExecContext(ctx, query, r.lottery, state.AsOf, state.Until,
+		state.Fingerprint.LastID, state.Fingerprint.LastDate, state.Fingerprint.Count,
+		state.Windows, state.Data, state.UpdatedAt)
+	return err
 }
 
-func (r *resultRepository) LastAnalysis(ctx context.Context) ([]byte, error) {
-	query := `SELECT data FROM analysis ORDER BY id DESC LIMIT 1`
+// Lock toma un lock de MySQL (GET_LOCK) con el nombre, propio de la lotería, para que varias
+// instancias no hagan a la vez el mismo trabajo. Devuelve la función que lo libera.
+func (r *resultRepository) Lock(ctx context.Context, name string, timeout time.Duration) (func(), error) {
+	// El lock pertenece a la conexión, así que se usa siempre la misma hasta liberarlo
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	row := r.db.QueryRowContext(ctx, query)
-	var data []byte
-	if err := row.Scan(&data); err != nil {
+	lockName := fmt.Sprintf("lottery-analyzer:%d:%s", r.lottery, name)
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		conn.Close()
 		return nil, err
 	}
-	return data, nil
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return nil, fmt.Errorf("timeout waiting for lock %s", lockName)
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), `DO RELEASE_LOCK(?)`, lockName)
+		conn.Close()
+	}, nil
 }
 
 func (r *resultRepository) CreateBatch(ctx context.Context, results []*model.Result) error {
@@ -223,15 +465,15 @@ func (r *resultRepository) CreateBatch(ctx context.Context, results []*model.Res
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO result (version, date, first, second, third, fourth, sign) 
-         VALUES (?, ?, ?, ?, ?, ?, ?)`)
+		`INSERT INTO result (lottery, version, date, first, second, third, fourth, sign) 
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, result := range results {
-		_, err := stmt.ExecContext(ctx,
+		_, err := stmt.ExecContext(ctx, r.lottery,
 			result.Version, result.Date, result.First, result.Second,
 			result.Third, result.Fourth, result.Sign)
 		if err != nil {
@@ -243,12 +485,12 @@ func (r *resultRepository) CreateBatch(ctx context.Context, results []*model.Res
 }
 
 func (r *resultRepository) ID(ctx context.Context, id int) (*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result WHERE id = ?`
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE id = ? AND lottery = ?`
 
 	var result model.Result
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&result.ID, &result.Version, &result.Date,
+	err := r.db.QueryRowContext(ctx, query, id, r.lottery).Scan(
+		&result.ID, &result.Lottery, &result.Version, &result.Date,
 		&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign)
 
 	if err == sql.ErrNoRows {
@@ -257,11 +499,11 @@ func (r *resultRepository) ID(ctx context.Context, id int) (*model.Result, error
 	return &result, err
 }
 
-func (r *resultRepository) Date(ctx context.Context, date string) ([]*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result WHERE date = ?`
+func (r *resultRepository) Date(ctx context.Context, date time.Time) ([]*model.Result, error) {
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE lottery = ? AND date = ?`
 
-	rows, err := r.db.QueryContext(ctx, query, date)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, date)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +512,7 @@ func (r *resultRepository) Date(ctx context.Context, date string) ([]*model.Resu
 	var results []*model.Result
 	for rows.Next() {
 		var result model.Result
-		if err := rows.Scan(&result.ID, &result.Version, &result.Date,
+		if err := rows.Scan(&result.ID, &result.Lottery, &result.Version, &result.Date,
 			&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign); err != nil {
 			return nil, err
 		}
@@ -281,10 +523,10 @@ func (r *resultRepository) Date(ctx context.Context, date string) ([]*model.Resu
 }
 
 func (r *resultRepository) LastNResults(ctx context.Context, limit int) ([]*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result ORDER BY id DESC LIMIT ?`
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE lottery = ? ORDER BY date DESC, id DESC LIMIT ?`
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -293,7 +535,7 @@ func (r *resultRepository) LastNResults(ctx context.Context, limit int) ([]*mode
 	var results []*model.Result
 	for rows.Next() {
 		var result model.Result
-		if err := rows.Scan(&result.ID, &result.Version, &result.Date,
+		if err := rows.Scan(&result.ID, &result.Lottery, &result.Version, &result.Date,
 			&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign); err != nil {
 			return nil, err
 		}
@@ -304,11 +546,11 @@ func (r *resultRepository) LastNResults(ctx context.Context, limit int) ([]*mode
 }
 
 func (r *resultRepository) BetweenDates(ctx context.Context, startDate, endDate time.Time) ([]*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result WHERE STR_TO_DATE(date, '%d/%m/%Y') BETWEEN ? AND ?
-              ORDER BY STR_TO_DATE(date, '%d/%m/%Y')`
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE lottery = ? AND date BETWEEN ? AND ?
+              ORDER BY date, id`
 
-	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -317,7 +559,7 @@ func (r *resultRepository) BetweenDates(ctx context.Context, startDate, endDate
 	var results []*model.Result
 	for rows.Next() {
 		var result model.Result
-		if err := rows.Scan(&result.ID, &result.Version, &result.Date,
+		if err := rows.Scan(&result.ID, &result.Lottery, &result.Version, &result.Date,
 			&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign); err != nil {
 			return nil, err
 		}
@@ -328,11 +570,11 @@ func (r *resultRepository) BetweenDates(ctx context.Context, startDate, endDate
 }
 
 func (r *resultRepository) AfterDate(ctx context.Context, date time.Time) ([]*model.Result, error) {
-	query := `SELECT id, version, date, first, second, third, fourth, sign 
-              FROM result WHERE STR_TO_DATE(date, '%d/%m/%Y') > ?
-              ORDER BY STR_TO_DATE(date, '%d/%m/%Y')`
+	query := `SELECT id, lottery, version, date, first, second, third, fourth, sign 
+              FROM result WHERE lottery = ? AND date > ?
+              ORDER BY date, id`
 
-	rows, err := r.db.QueryContext(ctx, query, date)
+	rows, err := r.db.QueryContext(ctx, query, r.lottery, date)
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +583,7 @@ func (r *resultRepository) AfterDate(ctx context.Context, date time.Time) ([]*mo
 	var results []*model.Result
 	for rows.Next() {
 		var result model.Result
-		if err := rows.Scan(&result.ID, &result.Version, &result.Date,
+		if err := rows.Scan(&result.ID, &result.Lottery, &result.Version, &result.Date,
 			&result.First, &result.Second, &result.Third, &result.Fourth, &result.Sign); err != nil {
 			return nil, err
 		}
@@ -351,28 +593,43 @@ func (r *resultRepository) AfterDate(ctx context.Context, date time.Time) ([]*mo
 	return results, rows.Err()
 }
 
+// Update corrige un sorteo y borra los conteos por ventana guardados de la lotería: la huella (cantidad,
+// último id y última fecha) no ve un cambio de dígitos, así que el estado quedaría mal.
 func (r *resultRepository) Update(ctx context.Context, result *model.Result) error {
-	query := `UPDATE result SET version = ?, date = ?, first = ?, second = ?, 
-              third = ?, fourth = ?, sign = ? WHERE id = ?`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE result SET lottery = ?, version = ?, date = ?, first = ?, second = ?, 
+              third = ?, fourth = ?, sign = ? WHERE id = ? AND lottery = ?`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query, r.lottery,
 		result.Version, result.Date, result.First, result.Second,
-		result.Third, result.Fourth, result.Sign, result.ID)
+		result.Third, result.Fourth, result.Sign, result.ID, r.lottery)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if _, err := tx.ExecContext(ctx, `DELETE FROM frequency_state WHERE lottery = ?`, r.lottery); err != nil {
+		return fmt.Errorf("failed to drop frequency state: %w", err)
+	}
+
+	return tx.Commit()
 }
 
 func (r *resultRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM result WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM result WHERE id = ? AND lottery = ?`
+	_, err := r.db.ExecContext(ctx, query, id, r.lottery)
 	return err
 }
 
-func (r *resultRepository) Exists(ctx context.Context, date string) (bool, error) {
-	query := `SELECT COUNT(*) FROM result WHERE date = ?`
+func (r *resultRepository) Exists(ctx context.Context, date time.Time) (bool, error) {
+	query := `SELECT COUNT(*) FROM result WHERE lottery = ? AND date = ?`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, date).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, r.lottery, date).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -381,18 +638,18 @@ func (r *resultRepository) Exists(ctx context.Context, date string) (bool, error
 }
 
 func (r *resultRepository) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM result`
+	query := `SELECT COUNT(*) FROM result WHERE lottery = ?`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, r.lottery).Scan(&count)
 	return count, err
 }
 
 func (r *resultRepository) CountBetweenDates(ctx context.Context, startDate, endDate time.Time) (int, error) {
 	query := `SELECT COUNT(*) FROM result 
-              WHERE STR_TO_DATE(date, '%d/%m/%Y') BETWEEN ? AND ?`
+              WHERE lottery = ? AND date BETWEEN ? AND ?`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, startDate, endDate).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, r.lottery, startDate, endDate).Scan(&count)
 	return count, err
 }
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+type ticketRepository struct {
+	db *sql.DB
+}
+
+// NewTicketRepository crea el repositorio de apuestas; a diferencia de los resultados no está
+// limitado a una lotería, cada apuesta lleva la suya.
+func NewTicketRepository(db *sql.DB) TicketRepository {
+	return &ticketRepository{db: db}
+}
+
+const ticketColumns = `id, lottery, username, strategy, date, number, sign, bet, stake, status, payout,
+              result_id, created_at, settled_at`
+
+func (r *ticketRepository) Create(ctx context.Context, ticket *model.PlayedTicket) error {
+	query := `INSERT INTO ticket (lottery, username, strategy, date, number, sign, bet, stake, status, created_at) 
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query, ticket.Lottery, ticket.User, ticket.Strategy, ticket.Date,
+		ticket.Number, ticket.Sign, ticket.Bet, ticket.Stake, ticket.Status, ticket.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	ticket.ID = int(id)
+	return nil
+}
+
+func (r *ticketRepository) ID(ctx context.Context, id int) (*model.PlayedTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ticket WHERE id = ?`
+
+	ticket, err := scanTicket(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ticket, err
+}
+
+// Pending devuelve las apuestas sin liquidar de una lotería y día de sorteo.
+func (r *ticketRepository) Pending(ctx context.Context, lottery int, date time.Time) ([]*model.PlayedTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ticket WHERE lottery = ? AND date = ? AND status = 'pending'`
+
+	rows, err := r.db.QueryContext(ctx, query, lottery, date)
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
+
+// Settleable devuelve las apuestas sin liquidar de una lotería cuyo sorteo ya está guardado.
+func (r *ticketRepository) Settleable(ctx context.Context, lottery int) ([]*model.PlayedTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ticket
+              WHERE lottery = ? AND status = 'pending'
+                AND EXISTS (SELECT 1 FROM result WHERE result.lottery = ticket.lottery AND result.date = ticket.date)
+              ORDER BY date, id`
+
+	rows, err := r.db.QueryContext(ctx, query, lottery)
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
+
+// Settle guarda el estado, el pago y el resultado con el que se liquidó la apuesta.
+func (r *ticketRepository) Settle(ctx context.Context, ticket *model.PlayedTicket) error {
+	query := `UPDATE ticket SET status = ?, payout = ?, result_id = ?, settled_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query, ticket.Status, ticket.Payout, ticket.ResultID, ticket.SettledAt, ticket.ID)
+	return err
+}
+
+func (r *ticketRepository) List(ctx context.Context, filter model.TicketFilter) ([]*model.PlayedTicket, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Lottery != 0 {
+		conditions = append(conditions, "lottery = ?")
+		args = append(args, filter.Lottery)
+	}
+	if filter.User != "" {
+		conditions = append(conditions, "username = ?")
+		args = append(args, filter.User)
+	}
+	if filter.Strategy != "" {
+		conditions = append(conditions, "strategy = ?")
+		args = append(args, filter.Strategy)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.Start.IsZero() {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, filter.Start)
+	}
+	if !filter.End.IsZero() {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, filter.End)
+	}
+
+	query := `SELECT ` + ticketColumns + ` FROM ticket`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY date, id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTicket(row rowScanner) (*model.PlayedTicket, error) {
+	var ticket model.PlayedTicket
+	var resultID sql.NullInt64
+	var settledAt sql.NullTime
+
+	err := row.Scan(&ticket.ID, &ticket.Lottery, &ticket.User, &ticket.Strategy, &ticket.Date,
+		&ticket.Number, &ticket.Sign, &ticket.Bet, &ticket.Stake, &ticket.Status, &ticket.Payout,
+		&resultID, &ticket.CreatedAt, &settledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.ResultID = int(resultID.Int64)
+	if settledAt.Valid {
+		ticket.SettledAt = &settledAt.Time
+	}
+	return &ticket, nil
+}
+
+func scanTickets(rows *sql.Rows) ([]*model.PlayedTicket, error) {
+	defer rows.Close()
+
+	var tickets []*model.PlayedTicket
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
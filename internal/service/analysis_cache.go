@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// analysisKey es la clave con la que se guarda y se busca un análisis ya calculado.
+func analysisKey(params model.AnalysisParams, fingerprint model.DataFingerprint) model.AnalysisKey {
+	return model.AnalysisKey{
+		Strategy:    params.Strategy,
+		ParamsHash:  paramsHash(params),
+		AsOf:        params.AsOf,
+		Fingerprint: fingerprint,
+	}
+}
+
+// paramsHash es el sha256 de los parámetros normalizados sin el modo, porque cada análisis trae
+// los rankings de todos los modos.
+func paramsHash(params model.AnalysisParams) string {
+	params.Mode = ""
+	data, _ := json.Marshal(params)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// spaceKey es la clave de los scores de los 10.000 números: sólo dependen de la estrategia, sus
+// opciones y las fechas, no de los filtros ni del tamaño del ranking.
+func spaceKey(params model.AnalysisParams, fingerprint model.DataFingerprint) model.AnalysisKey {
+	return analysisKey(model.AnalysisParams{
+		Strategy:      params.Strategy,
+		Normalization: params.Normalization,
+		FitDraws:      params.FitDraws,
+		AsOf:          params.AsOf,
+		Cutoff:        params.Cutoff,
+	}, fingerprint)
+}
+
+const (
+	// maxCachedAnalyses limita los análisis en memoria, uno por combinación de parámetros
+	maxCachedAnalyses = 32
+	// maxCachedSpaces limita los scores en memoria, que ocupan bastante más que un análisis
+	maxCachedSpaces = 8
+	// analysisLockTimeout y scrapeLockTimeout son la espera máxima por el lock de otra instancia
+	analysisLockTimeout = 10 * time.Minute
+	scrapeLockTimeout   = 10 * time.Minute
+	// scrapeInterval es el tiempo mínimo entre dos scrappings de la misma instancia
+	scrapeInterval = 15 * time.Minute
+)
+
+// analysisCache guarda en memoria el último análisis y los últimos scores de cada combinación de
+// parámetros, con la clave completa: sólo sirven mientras los sorteos no cambien. Lo guardado no se
+// modifica, así que se comparte entre las llamadas.
+type analysisCache struct {
+	mu       sync.Mutex
+	analyses map[string]cachedEntry
+	spaces   map[string]cachedEntry
+}
+
+type cachedEntry struct {
+	key    model.AnalysisKey
+	value  interface{}
+	stored time.Time
+}
+
+func newAnalysisCache() *analysisCache {
+	return &analysisCache{
+		analyses: make(map[string]cachedEntry),
+		spaces:   make(map[string]cachedEntry),
+	}
+}
+
+func (c *analysisCache) get(key model.AnalysisKey) *model.Analysis {
+	if value := c.lookup(c.analyses, key); value != nil {
+		return value.(*model.Analysis)
+	}
+	return nil
+}
+
+func (c *analysisCache) put(key model.AnalysisKey, analysis *model.Analysis) {
+	c.store(c.analyses, maxCachedAnalyses, key, analysis)
+}
+
+func (c *analysisCache) space(key model.AnalysisKey) *scoredSpace {
+	if value := c.lookup(c.spaces, key); value != nil {
+		return value.(*scoredSpace)
+	}
+	return nil
+}
+
+func (c *analysisCache) putSpace(key model.AnalysisKey, space *scoredSpace) {
+	c.store(c.spaces, maxCachedSpaces, key, space)
+}
+
+func (c *analysisCache) lookup(entries map[string]cachedEntry, key model.AnalysisKey) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := entries[key.ParamsHash]
+	if !ok || entry.key != key {
+		return nil
+	}
+	return entry.value
+}
+
+func (c *analysisCache) store(entries map[string]cachedEntry, limit int, key model.AnalysisKey, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Si no hay lugar se descarta el más viejo
+	if _, ok := entries[key.ParamsHash]; !ok && len(entries) >= limit {
+		oldest := ""
+		for hash, entry := range entries {
+			if oldest == "" || entry.stored.Before(entries[oldest].stored) {
+				oldest = hash
+			}
+		}
+		delete(entries, oldest)
+	}
+
+	entries[key.ParamsHash] = cachedEntry{key: key, value: value, stored: time.Now()}
+}
+
+func (c *analysisCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.analyses = make(map[string]cachedEntry)
+	c.spaces = make(map[string]cachedEntry)
+}
+
+// analysisFlight junta las llamadas simultáneas con la misma clave en un único cálculo.
+type analysisFlight struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newAnalysisFlight() *analysisFlight {
+	return &analysisFlight{calls: make(map[string]*flightCall)}
+}
+
+// do ejecuta fn si no hay otra llamada en curso con la clave, o espera su resultado. Todas las
+// llamadas reciben el mismo valor, que no se modifica. Si el cálculo compartido se cancela y el
+// contexto propio sigue vivo, se vuelve a intentar.
+func (f *analysisFlight) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	for {
+		f.mu.Lock()
+		call, running := f.calls[key]
+		if !running {
+			call = &flightCall{done: make(chan struct{})}
+			f.calls[key] = call
+		}
+		f.mu.Unlock()
+
+		if !running {
+			f.run(key, call, fn)
+		}
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if running && errors.Is(call.err, context.Canceled) && ctx.Err() == nil {
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.value, nil
+	}
+}
+
+// run ejecuta el cálculo compartido y libera a los que esperan aunque fn entre en pánico.
+func (f *analysisFlight) run(key string, call *flightCall, fn func() (interface{}, error)) {
+	call.err = errors.New("analysis did not finish")
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn()
+}
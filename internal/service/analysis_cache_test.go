@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestParamsHash(t *testing.T) {
+	base := normalizeParams(model.AnalysisParams{AsOf: "2024-05-01"})
+
+	tests := []struct {
+		name   string
+		params model.AnalysisParams
+		same   bool
+	}{
+		{"identical", base, true},
+		{"mode is ignored", func() model.AnalysisParams { p := base; p.Mode = ModeHot; return p }(), true},
+		{"defaults filled in", normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Strategy: StrategyFrequency, TopNumbers: defaultTopNumbers}), true},
+		{"ensemble options ignored for other strategies", normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Normalization: NormalizationZScore, FitDraws: 10}), true},
+		{"patterns", normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Patterns: "palindrome,double"}), false},
+		{"strategy", func() model.AnalysisParams { p := base; p.Strategy = StrategyPair; return p }(), false},
+		{"as-of", func() model.AnalysisParams { p := base; p.AsOf = "2024-05-02"; return p }(), false},
+		{"seed", func() model.AnalysisParams { p := base; p.Seed = 99; return p }(), false},
+		{"top numbers", func() model.AnalysisParams { p := base; p.TopNumbers = 50; return p }(), false},
+	}
+
+	want := paramsHash(base)
+	if len(want) != 64 {
+		t.Fatalf("hash %q is not a hex sha256", want)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paramsHash(tt.params); (got == want) != tt.same {
+				t.Errorf("same hash = %t, want %t", got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestPatternOrderDoesNotChangeTheHash(t *testing.T) {
+	a := normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Patterns: "palindrome,double"})
+	b := normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Patterns: "double, palindrome"})
+	if paramsHash(a) != paramsHash(b) {
+		t.Errorf("patterns %q and %q hash differently", a.Patterns, b.Patterns)
+	}
+}
+
+func TestAnalysisKey(t *testing.T) {
+	params := normalizeParams(model.AnalysisParams{AsOf: "2024-05-01", Strategy: StrategyPair})
+	fingerprint := model.DataFingerprint{LastID: 10, LastDate: "30/04/2024", Count: 10}
+
+	key := analysisKey(params, fingerprint)
+	if key.Strategy != StrategyPair || key.AsOf != "2024-05-01" || key.Fingerprint != fingerprint || key.ParamsHash != paramsHash(params) {
+		t.Errorf("key = %+v", key)
+	}
+
+	fingerprint.Count++
+	if analysisKey(params, fingerprint) == key {
+		t.Error("a new draw did not change the key")
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"lottery-analyzer/internal/model"
+)
+
+// Errores de las tablas guardadas que no existen, para distinguirlos de los fallos al leerlas.
+var (
+	ErrAnalysisNotFound    = errors.New("analysis not found")
+	ErrFrequenciesNotFound = errors.New("frequencies not found")
+	ErrRankingNotFound     = errors.New("ranking not found")
+)
+
+// frequenciesFormat es la versión del formato binario de las tablas guardadas con cada análisis.
+const frequenciesFormat = 1
+
+// encodeFrequencies guarda todas las tablas en el orden de frequencyTables: la versión y, por tabla,
+// la cantidad de valores (uvarint) y los float64 en little endian, todo comprimido con gzip.
+func encodeFrequencies(f *model.FrequencyData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	raw := []byte{frequenciesFormat}
+	for _, table := range frequencyTables(f) {
+		raw = binary.AppendUvarint(raw, uint64(len(table.values)))
+		for _, v := range table.values {
+			raw = binary.LittleEndian.AppendUint64(raw, math.Float64bits(v))
+		}
+	}
+
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeFrequencies lee las tablas guardadas por encodeFrequencies.
+func decodeFrequencies(data []byte) (*model.FrequencyData, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || raw[0] != frequenciesFormat {
+		return nil, fmt.Errorf("unknown frequencies format")
+	}
+	raw = raw[1:]
+
+	f := newFrequencyData()
+	for _, table := range frequencyTables(f) {
+		n, size := binary.Uvarint(raw)
+		if size <= 0 || int(n) != len(table.values) || len(raw[size:]) < 8*len(table.values) {
+			return nil, fmt.Errorf("invalid table %s.%s", table.group, table.name)
+		}
+		raw = raw[size:]
+		for i := range table.values {
+			table.values[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw))
+			raw = raw[8:]
+		}
+	}
+	if len(raw) > 0 {
+		return nil, fmt.Errorf("unexpected data after the last table")
+	}
+	return f, nil
+}
+
+// AnalysisFrequencies devuelve las tablas de frecuencias de un análisis guardado. group es digit,
+// two-digit, three-digit, four-digit o sign; name elige una tabla del grupo (p. ej. second_fourth, o
+// la posición 1-4 en digit y sign) y vacío devuelve todas.
+func (p *processorService) AnalysisFrequencies(ctx context.Context, id int, group, name string) (*model.AnalysisFrequencies, error) {
+	group = strings.ReplaceAll(strings.ToLower(group), "-", "_")
+	name = strings.ToLower(name)
+	if len(name) == 1 && name[0] >= '1' && name[0] <= '4' {
+		name = "position_" + name
+	}
+
+	data, err := p.resultRepo.AnalysisFrequencies(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frequencies of analysis %d: %w", id, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%w: %d", ErrAnalysisNotFound, id)
+	}
+	if len(*data) == 0 {
+		return nil, fmt.Errorf("%w: analysis %d was saved without them", ErrFrequenciesNotFound, id)
+	}
+
+	frequencies, err := decodeFrequencies(*data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frequencies of analysis %d: %w", id, err)
+	}
+
+	result := &model.AnalysisFrequencies{AnalysisID: id, Group: group, Tables: make(map[string][]float64)}
+	var names []string
+	for _, table := range frequencyTables(frequencies) {
+		if table.group != group {
+			continue
+		}
+		names = append(names, table.name)
+		if name == "" || table.name == name {
+			result.Tables[table.name] = table.values
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: unknown group %q (digit, two-digit, three-digit, four-digit, sign)",
+			ErrFrequenciesNotFound, group)
+	}
+	if len(result.Tables) == 0 {
+		return nil, fmt.Errorf("%w: unknown %s table %q (%s)", ErrFrequenciesNotFound, group, name, strings.Join(names, ", "))
+	}
+	return result, nil
+}
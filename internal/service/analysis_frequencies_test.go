@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func gzipped(raw []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(raw)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestEncodeDecodeFrequencies(t *testing.T) {
+	for _, seed := range []int64{1, 2} {
+		f := randomFrequencies(seed)
+		data, err := encodeFrequencies(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := decodeFrequencies(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := frequencyTables(f)
+		for i, table := range frequencyTables(decoded) {
+			for j, v := range table.values {
+				if v != want[i].values[j] {
+					t.Fatalf("seed %d: %s.%s[%d] = %v, want %v", seed, table.group, table.name, j, v, want[i].values[j])
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeFrequenciesErrors(t *testing.T) {
+	data, err := encodeFrequencies(randomFrequencies(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, _ := gzip.NewReader(bytes.NewReader(data))
+	var raw bytes.Buffer
+	raw.ReadFrom(zr)
+	valid := raw.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not gzip", []byte("frequencies")},
+		{"empty", gzipped(nil)},
+		{"unknown format", gzipped(append([]byte{frequenciesFormat + 1}, valid[1:]...))},
+		{"truncated", gzipped(valid[:len(valid)-8])},
+		{"trailing data", gzipped(append(append([]byte{}, valid...), 0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeFrequencies(tt.data); err == nil {
+				t.Error("decodeFrequencies accepted broken data")
+			}
+		})
+	}
+}
+
+func TestAnalysisFrequencies(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeResultRepo(1)
+	p := newTestProcessor(repo)
+
+	f := randomFrequencies(4)
+	data, err := encodeFrequencies(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := []byte("{}")
+	saved, _ := repo.SaveAnalysis(ctx, model.AnalysisKey{}, &empty, data)
+	withoutFrequencies, _ := repo.SaveAnalysis(ctx, model.AnalysisKey{}, &empty, nil)
+	otherLottery, _ := repo.WithLottery(2).SaveAnalysis(ctx, model.AnalysisKey{}, &empty, data)
+
+	tests := []struct {
+		name         string
+		id           int
+		group, table string
+		tables       []string
+		err          error
+	}{
+		{"whole group", saved, "two-digit", "", []string{"first_second", "first_third", "first_fourth", "second_third", "second_fourth", "third_fourth"}, nil},
+		{"one table", saved, "three_digit", "second_third_fourth", []string{"second_third_fourth"}, nil},
+		{"position shorthand", saved, "sign", "3", []string{"position_3"}, nil},
+		{"missing analysis", 99, "digit", "", nil, ErrAnalysisNotFound},
+		{"analysis of another lottery", otherLottery, "digit", "", nil, ErrAnalysisNotFound},
+		{"saved without frequencies", withoutFrequencies, "digit", "", nil, ErrFrequenciesNotFound},
+		{"unknown group", saved, "five-digit", "", nil, ErrFrequenciesNotFound},
+		{"unknown table", saved, "digit", "position_5", nil, ErrFrequenciesNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.AnalysisFrequencies(ctx, tt.id, tt.group, tt.table)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tables) != len(tt.tables) {
+				t.Fatalf("got %d tables, want %v", len(got.Tables), tt.tables)
+			}
+			for _, name := range tt.tables {
+				if _, ok := got.Tables[name]; !ok {
+					t.Errorf("table %s missing", name)
+				}
+			}
+		})
+	}
+}
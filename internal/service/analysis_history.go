@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"lottery-analyzer/internal/model"
+)
+
+const (
+	defaultAnalysisPage = 20
+	maxAnalysisPage     = 100
+)
+
+// Analyses lista los análisis guardados de la lotería, paginados.
+func (p *processorService) Analyses(ctx context.Context, filter model.AnalysisFilter) (*model.AnalysisPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAnalysisPage
+	}
+	if filter.Limit > maxAnalysisPage {
+		filter.Limit = maxAnalysisPage
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	records, total, err := p.resultRepo.Analyses(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list analyses: %w", err)
+	}
+
+	return &model.AnalysisPage{Analyses: records, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// AnalysisByID devuelve un análisis guardado completo.
+func (p *processorService) AnalysisByID(ctx context.Context, id int) (*model.Analysis, error) {
+	record, err := p.resultRepo.AnalysisByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis %d: %w", id, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("%w: %d", ErrAnalysisNotFound, id)
+	}
+	return savedAnalysis(record)
+}
+
+// DiffAnalyses compara el top-N del modo entre dos análisis guardados. Los análisis guardados antes
+// de existir los modos sólo tienen el ranking en frío, así que con otro modo no se pueden comparar.
+func (p *processorService) DiffAnalyses(ctx context.Context, fromID, toID int, mode string, top int) (*model.RankingDiff, error) {
+	if mode == "" {
+		mode = ModeCold
+	}
+	if err := validMode(mode); err != nil {
+		return nil, err
+	}
+	if top <= 0 {
+		top = defaultTopNumbers
+	}
+	if top > maxTopNumbers {
+		top = maxTopNumbers
+	}
+
+	from, err := p.AnalysisByID(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := p.AnalysisByID(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRanking, err := savedRanking(from, mode)
+	if err != nil {
+		return nil, err
+	}
+	toRanking, err := savedRanking(to, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffRankings(fromRanking.Numbers, fromRanking.Scores, toRanking.Numbers, toRanking.Scores, top)
+	diff.From, diff.To, diff.Mode = fromID, toID, mode
+	return diff, nil
+}
+
+// savedRanking devuelve el ranking del modo de un análisis guardado, sin caer en otro modo si no lo tiene.
+func savedRanking(analysis *model.Analysis, mode string) (model.Ranking, error) {
+	if len(analysis.Rankings) == 0 {
+		if mode != ModeCold {
+			return model.Ranking{}, fmt.Errorf("%w: analysis %d was saved before selection modes and only has the %s ranking",
+				ErrRankingNotFound, analysis.ID, ModeCold)
+		}
+		return model.Ranking{Mode: ModeCold, Numbers: analysis.BestNumbers, Scores: analysis.BestScores}, nil
+	}
+
+	ranking, ok := findRanking(analysis.Rankings, mode)
+	if !ok {
+		return model.Ranking{}, fmt.Errorf("%w: analysis %d has no %s ranking", ErrRankingNotFound, analysis.ID, mode)
+	}
+	return ranking, nil
+}
+
+func savedAnalysis(record *model.AnalysisRecord) (*model.Analysis, error) {
+	var analysis model.Analysis
+	if err := json.Unmarshal(record.Data, &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse analysis %d: %w", record.ID, err)
+	}
+	analysis.ID = record.ID
+	return &analysis, nil
+}
+
+// diffRankings compara los primeros top números de dos rankings. Los puestos se buscan en los
+// rankings completos, así un número que sale del top-N todavía muestra a qué puesto cayó.
+func diffRankings(fromNumbers []int, fromScores []float64, toNumbers []int, toScores []float64, top int) *model.RankingDiff {
+	fromRanks := rankPositions(fromNumbers)
+	toRanks := rankPositions(toNumbers)
+	diff := &model.RankingDiff{TopN: top}
+
+	change := func(number int) model.RankChange {
+		c := model.RankChange{Number: number, FromRank: fromRanks[number], ToRank: toRanks[number]}
+		if c.FromRank > 0 {
+			c.FromScore = &fromScores[c.FromRank-1]
+		}
+		if c.ToRank > 0 {
+			c.ToScore = &toScores[c.ToRank-1]
+		}
+		if c.FromScore != nil && c.ToScore != nil {
+			c.Movement = c.FromRank - c.ToRank
+			delta := *c.ToScore - *c.FromScore
+			c.ScoreDelta = &delta
+		}
+		return c
+	}
+
+	inTop := func(ranks map[int]int, number int) bool {
+		rank, ok := ranks[number]
+		return ok && rank <= top
+	}
+
+	for i, number := range toNumbers {
+		if i >= top {
+			break
+		}
+		if !inTop(fromRanks, number) {
+			diff.Entered = append(diff.Entered, change(number))
+			continue
+		}
+		diff.Kept++
+		if c := change(number); c.Movement != 0 {
+			diff.Moved = append(diff.Moved, c)
+		}
+	}
+
+	for i, number := range fromNumbers {
+		if i >= top {
+			break
+		}
+		if !inTop(toRanks, number) {
+			diff.Left = append(diff.Left, change(number))
+		}
+	}
+
+	// Primero los que más se movieron
+	sort.SliceStable(diff.Moved, func(i, j int) bool {
+		return abs(diff.Moved[i].Movement) > abs(diff.Moved[j].Movement)
+	})
+	return diff
+}
+
+// rankPositions devuelve el puesto (desde 1) de cada número del ranking.
+func rankPositions(numbers []int) map[int]int {
+	ranks := make(map[int]int, len(numbers))
+	for i, number := range numbers {
+		ranks[number] = i + 1
+	}
+	return ranks
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestDiffRankings(t *testing.T) {
+	fromNumbers := []int{10, 20, 30, 40, 50}
+	fromScores := []float64{1, 2, 3, 4, 5}
+	toNumbers := []int{30, 10, 60, 20, 40}
+	toScores := []float64{1.5, 2.5, 3.5, 4.5, 5.5}
+
+	diff := diffRankings(fromNumbers, fromScores, toNumbers, toScores, 3)
+
+	if diff.TopN != 3 || diff.Kept != 2 {
+		t.Errorf("top %d kept %d, want top 3 kept 2", diff.TopN, diff.Kept)
+	}
+
+	// 60 entra sin puesto anterior
+	if len(diff.Entered) != 1 || diff.Entered[0].Number != 60 || diff.Entered[0].FromRank != 0 || diff.Entered[0].ToRank != 3 {
+		t.Fatalf("entered = %+v", diff.Entered)
+	}
+	if diff.Entered[0].FromScore != nil || diff.Entered[0].ScoreDelta != nil || diff.Entered[0].Movement != 0 {
+		t.Errorf("entered number without a previous rank has a score or movement: %+v", diff.Entered[0])
+	}
+
+	// 20 sale del top 3 pero sigue en el ranking completo, en el puesto 4
+	if len(diff.Left) != 1 || diff.Left[0].Number != 20 || diff.Left[0].FromRank != 2 || diff.Left[0].ToRank != 4 {
+		t.Fatalf("left = %+v", diff.Left)
+	}
+	if diff.Left[0].Movement != -2 || *diff.Left[0].ScoreDelta != 2.5 {
+		t.Errorf("left movement %d delta %v, want -2 and 2.5", diff.Left[0].Movement, *diff.Left[0].ScoreDelta)
+	}
+
+	// 30 sube dos puestos y 10 baja uno; primero el que más se movió
+	if len(diff.Moved) != 2 || diff.Moved[0].Number != 30 || diff.Moved[0].Movement != 2 || diff.Moved[1].Number != 10 || diff.Moved[1].Movement != -1 {
+		t.Errorf("moved = %+v", diff.Moved)
+	}
+}
+
+func TestDiffRankingsIdentical(t *testing.T) {
+	numbers := []int{1, 2, 3}
+	scores := []float64{0.1, 0.2, 0.3}
+
+	diff := diffRankings(numbers, scores, numbers, scores, 10)
+	if diff.Kept != 3 || len(diff.Entered) != 0 || len(diff.Left) != 0 || len(diff.Moved) != 0 {
+		t.Errorf("diff of identical rankings = %+v", diff)
+	}
+}
+
+func TestDiffRankingsLeftTheRanking(t *testing.T) {
+	diff := diffRankings([]int{1, 2}, []float64{1, 2}, []int{3, 1}, []float64{1, 2}, 2)
+
+	if len(diff.Left) != 1 || diff.Left[0].Number != 2 || diff.Left[0].ToRank != 0 || diff.Left[0].ToScore != nil {
+		t.Errorf("left = %+v, want 2 with no rank in the new ranking", diff.Left)
+	}
+}
+
+func TestDiffAnalysesModes(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeResultRepo(1)
+	save := func(analysis model.Analysis) int {
+		data, err := json.Marshal(analysis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := repo.SaveAnalysis(ctx, model.AnalysisKey{}, &data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	// Antes de existir los modos sólo se guardaba el ranking en frío
+	legacy := save(model.Analysis{BestNumbers: []int{1, 2, 3}, BestScores: []float64{1, 2, 3}})
+	modes := save(model.Analysis{
+		Mode:        ModeCold,
+		BestNumbers: []int{2, 1, 3},
+		BestScores:  []float64{1, 2, 3},
+		Rankings: []model.Ranking{
+			{Mode: ModeCold, Numbers: []int{2, 1, 3}, Scores: []float64{1, 2, 3}},
+			{Mode: ModeHot, Numbers: []int{9, 8, 7}, Scores: []float64{9, 8, 7}},
+		},
+	})
+	p := newTestProcessor(repo)
+
+	tests := []struct {
+		name     string
+		from, to int
+		mode     string
+		wantErr  error
+		moved    int
+	}{
+		// 1 y 2 cambian de puesto entre el ranking viejo y el frío nuevo
+		{"legacy against cold", legacy, modes, ModeCold, nil, 2},
+		{"default mode is cold", legacy, modes, "", nil, 2},
+		{"hot against hot", modes, modes, ModeHot, nil, 0},
+		{"legacy has no hot ranking", legacy, modes, ModeHot, ErrRankingNotFound, 0},
+		{"target legacy has no hot ranking", modes, legacy, ModeHot, ErrRankingNotFound, 0},
+		{"mode missing from the rankings", modes, modes, ModeDistance, ErrRankingNotFound, 0},
+		{"analysis missing", legacy, 99, ModeCold, ErrAnalysisNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := p.DiffAnalyses(ctx, tt.from, tt.to, tt.mode, 3)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tt.mode
+			if want == "" {
+				want = ModeCold
+			}
+			if diff.Mode != want || diff.Kept != 3 || len(diff.Moved) != tt.moved {
+				t.Errorf("diff in mode %s kept %d and moved %d, want mode %s, 3 kept and %d moved",
+					diff.Mode, diff.Kept, len(diff.Moved), want, tt.moved)
+			}
+		})
+	}
+
+	if _, err := p.DiffAnalyses(ctx, legacy, modes, "warm", 3); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+}
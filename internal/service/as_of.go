@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// AsOfLayout es el formato de la fecha de análisis y del corte de sorteos en los parámetros.
+const AsOfLayout = time.DateOnly
+
+// day trunca t al día en UTC, igual que las fechas de los sorteos, para que las ventanas no dependan
+// de la hora en que se ejecuta el análisis.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// today es la fecha de análisis por defecto.
+func today() string {
+	return time.Now().Format(AsOfLayout)
+}
+
+// analysisDates devuelve el día en que se anclan las ventanas y el último día de sorteos que entra
+// en el análisis.
+func analysisDates(params model.AnalysisParams) (time.Time, time.Time, error) {
+	asOf, err := time.Parse(AsOfLayout, params.AsOf)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid as-of date: %s", params.AsOf)
+	}
+
+	cutoff, err := time.Parse(AsOfLayout, params.Cutoff)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid cutoff date: %s", params.Cutoff)
+	}
+
+	if cutoff.After(asOf) {
+		return time.Time{}, time.Time{}, fmt.Errorf("cutoff %s is after as-of date %s", params.Cutoff, params.AsOf)
+	}
+
+	return asOf, cutoff, nil
+}
+
+// unplayedFrom cuenta los números que no salieron en history.
+func unplayedFrom(history []*model.Result) int {
+	var played [10000]bool
+	unplayed := len(played)
+	for _, result := range history {
+		number := result.First*1000 + result.Second*100 + result.Third*10 + result.Fourth
+		if number < 0 || number >= len(played) || played[number] {
+			continue
+		}
+		played[number] = true
+		unplayed--
+	}
+	return unplayed
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// Niveles de acierto que se comparan contra el azar
+const (
+	MatchExact     = "exact"
+	MatchLastThree = "last_three"
+	MatchLastTwo   = "last_two"
+)
+
+const (
+	defaultSimulations   = 1000
+	maxSimulations       = 20000
+	defaultSeed          = 1
+	defaultBaselineDraws = 365
+	significanceLevel    = 0.05
+)
+
+// hitCounter cuenta cuántos sorteos acierta una selección de números en cada nivel.
+type hitCounter struct {
+	exact     [10000]bool
+	lastThree [1000]bool
+	lastTwo   [100]bool
+}
+
+func (h *hitCounter) reset(numbers []int) {
+	*h = hitCounter{}
+	for _, n := range numbers {
+		h.exact[n] = true
+		h.lastThree[n%1000] = true
+		h.lastTwo[n%100] = true
+	}
+}
+
+func (h *hitCounter) hits(draws []int) [3]int {
+	var hits [3]int
+	for _, d := range draws {
+		if h.exact[d] {
+			hits[0]++
+		}
+		if h.lastThree[d%1000] {
+			hits[1]++
+		}
+		if h.lastTwo[d%100] {
+			hits[2]++
+		}
+	}
+	return hits
+}
+
+// SimulateBaseline compara los aciertos históricos del ranking con los de selecciones aleatorias
+// del mismo tamaño. Con la misma semilla el resultado es siempre el mismo.
+func SimulateBaseline(ranking []int, draws []int, simulations int, seed int64) *model.BaselineComparison {
+	levels := []string{MatchExact, MatchLastThree, MatchLastTwo}
+
+	counter := &hitCounter{}
+	counter.reset(ranking)
+	observed := counter.hits(draws)
+
+	rng := rand.New(rand.NewSource(seed))
+	universe := make([]int, 10000)
+	for i := range universe {
+		universe[i] = i
+	}
+
+	random := make([][]float64, len(levels))
+	atLeast := make([]int, len(levels))
+	distributions := make([][]int, len(levels))
+
+	for s := 0; s < simulations; s++ {
+		// Fisher-Yates parcial: los primeros len(ranking) elementos son la selección aleatoria
+		for i := 0; i < len(ranking); i++ {
+			j := i + rng.Intn(len(universe)-i)
+			universe[i], universe[j] = universe[j], universe[i]
+		}
+		counter.reset(universe[:len(ranking)])
+		hits := counter.hits(draws)
+
+		for l := range levels {
+			random[l] = append(random[l], float64(hits[l]))
+			if hits[l] >= observed[l] {
+				atLeast[l]++
+			}
+			for len(distributions[l]) <= hits[l] {
+				distributions[l] = append(distributions[l], 0)
+			}
+			distributions[l][hits[l]]++
+		}
+	}
+
+	comparison := &model.BaselineComparison{
+		Simulations: simulations,
+		Seed:        seed,
+		Draws:       len(draws),
+		TopN:        len(ranking),
+	}
+
+	for l, level := range levels {
+		mean, std := utils.MeanStd(random[l])
+		comparison.Levels = append(comparison.Levels, model.BaselineLevel{
+			Match:        level,
+			Hits:         observed[l],
+			RandomMean:   mean,
+			RandomStd:    std,
+			PValue:       float64(1+atLeast[l]) / float64(1+simulations),
+			Distribution: distributions[l],
+		})
+	}
+
+	// El nivel exacto es el que decide, es el premio mayor
+	comparison.BetterThanRandom = comparison.Levels[0].PValue < significanceLevel
+
+	return comparison
+}
+
+// holdoutBaselines compara contra el azar, en cada modo, el ranking que se habría armado el día del
+// primero de los últimos params.BaselineDraws sorteos, sin ver ninguno de ellos: las frecuencias
+// salen sólo de los sorteos anteriores y los aciertos se cuentan en los reservados.
+func (p *processorService) holdoutBaselines(ctx context.Context, params model.AnalysisParams, history []*model.Result, filter *numberFilter) (map[string]*model.BaselineComparison, error) {
+	draws, err := newDraws(history)
+	if err != nil {
+		return nil, err
+	}
+
+	train := holdoutSplit(draws, params.BaselineDraws)
+	heldOut := make([]int, 0, len(draws)-train)
+	for _, d := range draws[train:] {
+		heldOut = append(heldOut, d.result.First*1000+d.result.Second*100+d.result.Third*10+d.result.Fourth)
+	}
+
+	var from time.Time
+	if train < len(draws) {
+		from = draws[train].date
+	}
+
+	results := make([]*model.Result, train)
+	for i, d := range draws[:train] {
+		results[i] = d.result
+	}
+
+	// Una estrategia nueva para no pisar el estado de la del análisis
+	strategy, err := NewStrategy(params)
+	if err != nil {
+		return nil, err
+	}
+	frequencies, _ := frequenciesFromDraws(draws[:train], from)
+	scores, err := strategy.Scores(ctx, &StrategyInput{Frequencies: frequencies, History: results, AsOf: from})
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s failed on the held-out draws: %w", strategy.Name(), err)
+	}
+
+	baselines := make(map[string]*model.BaselineComparison, len(SelectionModes))
+	for _, mode := range SelectionModes {
+		numbers, _ := p.rankNumbers(modeKeys(scoresFor(strategy, scores, mode), mode), filter, params.TopNumbers)
+		baseline := SimulateBaseline(numbers, heldOut, params.Simulations, params.Seed)
+		baseline.HoldoutFrom = from
+		baselines[mode] = baseline
+	}
+	return baselines, nil
+}
+
+// holdoutSplit devuelve cuántos sorteos del principio quedan para entrenar si se reservan los últimos
+// n; los del mismo día que el primero reservado también se reservan.
+func holdoutSplit(draws []draw, n int) int {
+	if n >= len(draws) {
+		return 0
+	}
+	first := draws[len(draws)-n].date
+	return sort.Search(len(draws), func(i int) bool { return !draws[i].date.Before(first) })
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+func testDraws(dates ...string) []draw {
+	draws := make([]draw, len(dates))
+	for i, d := range dates {
+		date, err := time.Parse(time.DateOnly, d)
+		if err != nil {
+			panic(err)
+		}
+		draws[i] = draw{date: date, result: &model.Result{Date: date}}
+	}
+	return draws
+}
+
+func TestHoldoutSplit(t *testing.T) {
+	draws := testDraws("2024-01-01", "2024-01-02", "2024-01-03", "2024-01-03", "2024-01-04")
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{"last draw", 1, 4},
+		{"same day draws stay together", 2, 2},
+		{"three draws", 3, 2},
+		{"all draws", 5, 0},
+		{"more than available", 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdoutSplit(draws, tt.n); got != tt.want {
+				t.Errorf("holdoutSplit(%d) = %d, want %d", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHitCounter(t *testing.T) {
+	counter := &hitCounter{}
+	counter.reset([]int{1234, 5678})
+
+	tests := []struct {
+		draw int
+		want [3]int
+	}{
+		{1234, [3]int{1, 1, 1}},
+		{9234, [3]int{0, 1, 1}},
+		{9934, [3]int{0, 0, 1}},
+		{9999, [3]int{0, 0, 0}},
+		{678, [3]int{0, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		if got := counter.hits([]int{tt.draw}); got != tt.want {
+			t.Errorf("hits(%04d) = %v, want %v", tt.draw, got, tt.want)
+		}
+	}
+}
+
+func TestSimulateBaseline(t *testing.T) {
+	ranking := []int{1, 2, 3, 4, 5}
+	draws := []int{1, 2, 3, 9000, 9001}
+
+	first := SimulateBaseline(ranking, draws, 200, 7)
+	second := SimulateBaseline(ranking, draws, 200, 7)
+
+	if first.Levels[0].Hits != 3 {
+		t.Errorf("exact hits = %d, want 3", first.Levels[0].Hits)
+	}
+	for l := range first.Levels {
+		if first.Levels[l].PValue != second.Levels[l].PValue || first.Levels[l].RandomMean != second.Levels[l].RandomMean {
+			t.Errorf("level %s differs between runs with the same seed", first.Levels[l].Match)
+		}
+		total := 0
+		for _, count := range first.Levels[l].Distribution {
+			total += count
+		}
+		if total != 200 {
+			t.Errorf("level %s distribution has %d simulations, want 200", first.Levels[l].Match, total)
+		}
+	}
+	if !first.BetterThanRandom {
+		t.Errorf("3 exact hits out of 5 draws with 5 numbers should beat random, p = %v", first.Levels[0].PValue)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// maxBootstrap limita los remuestreos: cada uno vuelve a puntuar los 10.000 números con la estrategia
+const maxBootstrap = 500
+
+// bootstrapStability remuestrea con reemplazo los sorteos del histórico, vuelve a puntuar con la
+// misma estrategia y filtro y guarda en cada ranking cómo se movieron sus números. Con la misma
+// semilla el resultado es siempre el mismo.
+func (p *processorService) bootstrapStability(ctx context.Context, params model.AnalysisParams, history []*model.Result, asOf time.Time, filter *numberFilter, rankings []model.Ranking) error {
+	draws, err := newDraws(history)
+	if err != nil {
+		return err
+	}
+	if len(draws) == 0 || params.Bootstrap <= 0 {
+		return nil
+	}
+
+	// ranks[ranking][número del ranking][remuestreo]
+	ranks := make([][][]int, len(rankings))
+	for i, ranking := range rankings {
+		ranks[i] = make([][]int, len(ranking.Numbers))
+		for j := range ranks[i] {
+			ranks[i][j] = make([]int, params.Bootstrap)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(params.Seed))
+	sample := make([]draw, len(draws))
+	position := make([]int, 10000)
+
+	for resample := 0; resample < params.Bootstrap; resample++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for i := range sample {
+			sample[i] = draws[rng.Intn(len(draws))]
+		}
+		sort.SliceStable(sample, func(i, j int) bool { return sample[i].date.Before(sample[j].date) })
+
+		results := make([]*model.Result, len(sample))
+		for i, dr := range sample {
+			results[i] = dr.result
+		}
+
+		// Una estrategia nueva en cada remuestreo para no pisar el estado de la del análisis
+		strategy, err := NewStrategy(params)
+		if err != nil {
+			return err
+		}
+
+		frequencies, _ := frequenciesFromDraws(sample, asOf)
+		scores, err := strategy.Scores(ctx, &StrategyInput{Frequencies: frequencies, History: results, AsOf: asOf})
+		if err != nil {
+			return fmt.Errorf("bootstrap resample %d failed: %w", resample, err)
+		}
+
+		for i, ranking := range rankings {
+			order, _ := p.rankNumbers(modeKeys(scoresFor(strategy, scores, ranking.Mode), ranking.Mode), filter, len(position))
+			for rank, number := range order {
+				position[number] = rank + 1
+			}
+			for j, number := range ranking.Numbers {
+				ranks[i][j][resample] = position[number]
+			}
+		}
+	}
+
+	for i := range rankings {
+		rankings[i].Stability = make([]model.RankStability, len(rankings[i].Numbers))
+		for j, number := range rankings[i].Numbers {
+			rankings[i].Stability[j] = rankStability(number, ranks[i][j])
+		}
+	}
+
+	return nil
+}
+
+func rankStability(number int, ranks []int) model.RankStability {
+	sorted := append([]int(nil), ranks...)
+	sort.Ints(sorted)
+
+	stability := model.RankStability{Number: number}
+	sum := 0
+	for _, rank := range sorted {
+		sum += rank
+		if rank <= 10 {
+			stability.Top10++
+		}
+		if rank <= 50 {
+			stability.Top50++
+		}
+		if rank <= 100 {
+			stability.Top100++
+		}
+	}
+
+	total := float64(len(sorted))
+	stability.Top10 /= total
+	stability.Top50 /= total
+	stability.Top100 /= total
+	stability.MeanRank = float64(sum) / total
+	stability.RankP05 = quantile(sorted, 0.05)
+	stability.RankP25 = quantile(sorted, 0.25)
+	stability.RankP50 = quantile(sorted, 0.50)
+	stability.RankP75 = quantile(sorted, 0.75)
+	stability.RankP95 = quantile(sorted, 0.95)
+	return stability
+}
+
+// quantile es el cuantil q de valores ya ordenados por el método del rango más cercano.
+func quantile(sorted []int, q float64) int {
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
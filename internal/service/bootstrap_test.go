@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// testHistory arma n sorteos diarios al azar, reproducibles con la semilla.
+func testHistory(n int, seed int64) []*model.Result {
+	rng := rand.New(rand.NewSource(seed))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]*model.Result, n)
+	for i := range history {
+		history[i] = &model.Result{
+			ID:     i + 1,
+			Date:   start.AddDate(0, 0, i),
+			First:  rng.Intn(10),
+			Second: rng.Intn(10),
+			Third:  rng.Intn(10),
+			Fourth: rng.Intn(10),
+			Sign:   model.Signs[rng.Intn(len(model.Signs))],
+		}
+	}
+	return history
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		q    float64
+		want int
+	}{
+		{0, 1},
+		{0.05, 1},
+		{0.25, 3},
+		{0.5, 5},
+		{0.95, 10},
+		{1, 10},
+	}
+
+	for _, tt := range tests {
+		if got := quantile(sorted, tt.q); got != tt.want {
+			t.Errorf("quantile(%v) = %d, want %d", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestRankStability(t *testing.T) {
+	got := rankStability(42, []int{200, 1, 60, 5})
+	want := model.RankStability{
+		Number:   42,
+		Top10:    0.5,
+		Top50:    0.5,
+		Top100:   0.75,
+		RankP05:  1,
+		RankP25:  1,
+		RankP50:  5,
+		RankP75:  60,
+		RankP95:  200,
+		MeanRank: 66.5,
+	}
+	if got != want {
+		t.Errorf("rankStability = %+v, want %+v", got, want)
+	}
+}
+
+func TestBootstrapStabilityIsReproducible(t *testing.T) {
+	history := testHistory(120, 1)
+	asOf := history[len(history)-1].Date.AddDate(0, 0, 1)
+	params := normalizeParams(model.AnalysisParams{Bootstrap: 5, Seed: 3, TopNumbers: 10})
+	filter, err := newNumberFilter(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &processorService{}
+	run := func() []model.Ranking {
+		rankings := []model.Ranking{
+			{Mode: ModeCold, Numbers: []int{1, 2, 3}},
+			{Mode: ModeHot, Numbers: []int{4, 5}},
+		}
+		if err := p.bootstrapStability(context.Background(), params, history, asOf, filter, rankings); err != nil {
+			t.Fatal(err)
+		}
+		return rankings
+	}
+
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("bootstrap with the same seed gave different stability")
+	}
+
+	for _, ranking := range first {
+		if len(ranking.Stability) != len(ranking.Numbers) {
+			t.Fatalf("%s: %d stability entries for %d numbers", ranking.Mode, len(ranking.Stability), len(ranking.Numbers))
+		}
+		for i, s := range ranking.Stability {
+			if s.Number != ranking.Numbers[i] {
+				t.Errorf("%s: stability %d is for %d, want %d", ranking.Mode, i, s.Number, ranking.Numbers[i])
+			}
+			if s.RankP05 < 1 || s.RankP05 > s.RankP50 || s.RankP50 > s.RankP95 || s.RankP95 > 10000 {
+				t.Errorf("%s: ranks out of order: %+v", ranking.Mode, s)
+			}
+		}
+	}
+}
+
+func TestBootstrapStabilityDisabled(t *testing.T) {
+	rankings := []model.Ranking{{Mode: ModeCold, Numbers: []int{1}}}
+	params := normalizeParams(model.AnalysisParams{})
+	filter, _ := newNumberFilter(params)
+
+	p := &processorService{}
+	if err := p.bootstrapStability(context.Background(), params, testHistory(10, 1), time.Now(), filter, rankings); err != nil {
+		t.Fatal(err)
+	}
+	if rankings[0].Stability != nil {
+		t.Errorf("stability computed with bootstrap 0: %+v", rankings[0].Stability)
+	}
+}
@@ -12,102 +12,134 @@ import (
 
 // CalculateFrequencies calculates the frequencies of digits, two-digit, three-digit, and four-digit numbers
 // Ya después se pueden calcular las probabilidades de un número de cuatro cifras usando las probabilidades de sus combinaciones.
+// Las ventanas se anclan en el día asOf y sólo cuentan los sorteos hasta el día until inclusive.
 
-func CalculateFrequencies(ctx context.Context, resultRepo repository.ResultRepository) (*model.FrequencyData, int, error) {
+func CalculateFrequencies(ctx context.Context, resultRepo repository.ResultRepository, asOf, until time.Time) (*model.FrequencyData, int, error) {
 
-	before, actual, frequenciesProcessed := 1, 1, 0
+	frequenciesProcessed := 0
+	frequencyData := newFrequencyData()
 
-	// contenedor de las probabilidades por dígito
-	digit := &model.DigitFrequency{
-		Position1: make([]float64, 10),
-		Position2: make([]float64, 10),
-		Position3: make([]float64, 10),
-		Position4: make([]float64, 10),
-	}
+	digit := &frequencyData.DigitFreq
+	twoDigit := &frequencyData.TwoDigitFreq
+	threeDigit := &frequencyData.ThreeDigitFreq
+	fourDigit := &frequencyData.FourDigitFreq
+	sign := &frequencyData.SignFreq
 
-	twoDigit := &model.TwoDigitFrequency{
-		FirstSecond:  make([]float64, 100),
-		FirstThird:   make([]float64, 100),
-		FirstFourth:  make([]float64, 100),
-		SecondThird:  make([]float64, 100),
-		SecondFourth: make([]float64, 100),
-		ThirdFourth:  make([]float64, 100),
-	}
+	windows := fibonacciWindows(day(asOf))
+	for _, date := range windows {
 
-	threeDigit := &model.ThreeDigitFrequency{
-		FirstSecondThird:  make([]float64, 1000),
-		FirstSecondFourth: make([]float64, 1000),
-		FirstThirdFourth:  make([]float64, 1000),
-		SecondThirdFourth: make([]float64, 1000),
-	}
-
-	fourDigit := &model.FourDigitFrequency{
-		Complete: make([]float64, 10000),
-	}
-
-	frequencyData := &model.FrequencyData{
-		DigitFreq:      *digit,
-		TwoDigitFreq:   *twoDigit,
-		ThreeDigitFreq: *threeDigit,
-		FourDigitFreq:  *fourDigit,
-	}
-
-	// to-do este 5000 hay que volverlo automático y pensarse la lógica que está usando para optimizarla.
-	for actual < 5000 {
-
-		date := time.Now().AddDate(0, 0, -(actual + 7))
-
-		if err := digitFrequencies(ctx, date, resultRepo, digit); err != nil {
+		if err := digitFrequencies(ctx, date, until, resultRepo, digit); err != nil {
 			return nil, 0, fmt.Errorf("digit frequency query failed: %w", err)
 		}
 
-		if err := twoDigitFrequencies(ctx, date, resultRepo, twoDigit); err != nil {
+		if err := twoDigitFrequencies(ctx, date, until, resultRepo, twoDigit); err != nil {
 			return nil, 0, fmt.Errorf("two digit frequency query failed: %w", err)
 		}
 
-		if err := threeDigitFrequencies(ctx, date, resultRepo, threeDigit); err != nil {
+		if err := threeDigitFrequencies(ctx, date, until, resultRepo, threeDigit); err != nil {
 			return nil, 0, fmt.Errorf("three digit frequency query failed: %w", err)
 		}
 
-		if err := fourDigitFrequencies(ctx, date, resultRepo, fourDigit); err != nil {
+		if err := fourDigitFrequencies(ctx, date, until, resultRepo, fourDigit); err != nil {
 			return nil, 0, fmt.Errorf("four digit frequency query failed: %w", err)
 		}
 
-		before, actual = actual, before+actual
-		//fmt.Printf("actual: %d \n", actual)
+		if err := signFrequencies(ctx, date, until, resultRepo, sign); err != nil {
+			return nil, 0, fmt.Errorf("sign frequency query failed: %w", err)
+		}
+
 		frequenciesProcessed++
+		reportWindows(ctx, frequenciesProcessed, len(windows))
 	}
 
 	return frequencyData, frequenciesProcessed, nil
 }
 
+// fibonacciWindows devuelve las fechas de corte de cada ventana: se cuentan los sorteos posteriores
+// a from menos (fibonacci + 7) días, con la secuencia 1, 2, 3, 5, 8...
+func fibonacciWindows(from time.Time) []time.Time {
+	var windows []time.Time
+	before, actual := 1, 1
+
+	// to-do este 5000 hay que volverlo automático y pensarse la lógica que está usando para optimizarla.
+	for actual < 5000 {
+		windows = append(windows, from.AddDate(0, 0, -(actual+7)))
+		before, actual = actual, before+actual
+	}
+
+	return windows
+}
+
+// newFrequencyData crea los contenedores vacíos de todas las frecuencias.
+func newFrequencyData() *model.FrequencyData {
+	return &model.FrequencyData{
+		DigitFreq: model.DigitFrequency{
+			Position1: make([]float64, 10),
+			Position2: make([]float64, 10),
+			Position3: make([]float64, 10),
+			Position4: make([]float64, 10),
+		},
+		TwoDigitFreq: model.TwoDigitFrequency{
+			FirstSecond:  make([]float64, 100),
+			FirstThird:   make([]float64, 100),
+			FirstFourth:  make([]float64, 100),
+			SecondThird:  make([]float64, 100),
+			SecondFourth: make([]float64, 100),
+			ThirdFourth:  make([]float64, 100),
+		},
+		ThreeDigitFreq: model.ThreeDigitFrequency{
+			FirstSecondThird:  make([]float64, 1000),
+			FirstSecondFourth: make([]float64, 1000),
+			FirstThirdFourth:  make([]float64, 1000),
+			SecondThirdFourth: make([]float64, 1000),
+		},
+		FourDigitFreq: model.FourDigitFrequency{
+			Complete: make([]float64, 10000),
+		},
+		SignFreq: model.SignFrequency{
+			Signs:     make([]float64, len(model.Signs)),
+			Position1: make([]float64, len(model.Signs)*10),
+			Position2: make([]float64, len(model.Signs)*10),
+			Position3: make([]float64, len(model.Signs)*10),
+			Position4: make([]float64, len(model.Signs)*10),
+		},
+	}
+}
+
+// Factores de ponderación por posición, susceptibles de ser parámetros si hago computación evolutiva to-do-ia
+var (
+	digitFactors      = []float64{1.0, 1.9, 2.69, 2.69}
+	twoDigitFactors   = []float64{1.0, 1.45, 1.45, 1.55, 1.55, 5.0}
+	threeDigitFactors = []float64{1.0, 1.0, 5.0, 5.0}
+)
+
 // Implementar métodos de consulta de frecuencias por commbinación de uno, dos y tres dígitos, también con todos los dígitos.
 
-func digitFrequencies(ctx context.Context, fromDate time.Time, resultRepo repository.ResultRepository, digitCount *model.DigitFrequency) error {
-	factors := []float64{1.0, 1.9, 2.69, 2.69} //susceptible de ser parámetros si hago computación evolutiva, por eso lo dejo como variable to-do-ia
+func digitFrequencies(ctx context.Context, fromDate, until time.Time, resultRepo repository.ResultRepository, digitCount *model.DigitFrequency) error {
+	factors := digitFactors
 
-	counts, err := resultRepo.OneDigit(ctx, fromDate, "first")
+	counts, err := resultRepo.OneDigit(ctx, fromDate, until, "first")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by digit-first: %w", err)
 	} else {
 		digitCount.Position1 = sumProbDigit(counts, digitCount.Position1, factors[0])
 	}
 
-	counts, err = resultRepo.OneDigit(ctx, fromDate, "second")
+	counts, err = resultRepo.OneDigit(ctx, fromDate, until, "second")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by digit-second: %w", err)
 	} else {
 		digitCount.Position2 = sumProbDigit(counts, digitCount.Position2, factors[1])
 	}
 
-	counts, err = resultRepo.OneDigit(ctx, fromDate, "third")
+	counts, err = resultRepo.OneDigit(ctx, fromDate, until, "third")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by digit-third: %w", err)
 	} else {
 		digitCount.Position3 = sumProbDigit(counts, digitCount.Position3, factors[2])
 	}
 
-	counts, err = resultRepo.OneDigit(ctx, fromDate, "fourth")
+	counts, err = resultRepo.OneDigit(ctx, fromDate, until, "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by digit-fourth: %w", err)
 	} else {
@@ -131,45 +163,45 @@ func sumProbDigit(results []*model.DigitCount, probAccumulated []float64, factor
 	return probAccumulated
 }
 
-func twoDigitFrequencies(ctx context.Context, fromDate time.Time, resultRepo repository.ResultRepository, twoDigitCount *model.TwoDigitFrequency) error {
-	factors := []float64{1.0, 1.45, 1.45, 1.55, 1.55, 5.0}
+func twoDigitFrequencies(ctx context.Context, fromDate, until time.Time, resultRepo repository.ResultRepository, twoDigitCount *model.TwoDigitFrequency) error {
+	factors := twoDigitFactors
 
-	counts, err := resultRepo.TwoDigit(ctx, fromDate, "first", "second")
+	counts, err := resultRepo.TwoDigit(ctx, fromDate, until, "first", "second")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-first-second: %w", err)
 	} else {
 		twoDigitCount.FirstSecond = sumProbTwoDigit(counts, twoDigitCount.FirstSecond, factors[0])
 	}
 
-	counts, err = resultRepo.TwoDigit(ctx, fromDate, "first", "third")
+	counts, err = resultRepo.TwoDigit(ctx, fromDate, until, "first", "third")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-first-third: %w", err)
 	} else {
 		twoDigitCount.FirstThird = sumProbTwoDigit(counts, twoDigitCount.FirstThird, factors[1])
 	}
 
-	counts, err = resultRepo.TwoDigit(ctx, fromDate, "first", "fourth")
+	counts, err = resultRepo.TwoDigit(ctx, fromDate, until, "first", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-first-fourth: %w", err)
 	} else {
 		twoDigitCount.FirstFourth = sumProbTwoDigit(counts, twoDigitCount.FirstFourth, factors[2])
 	}
 
-	counts, err = resultRepo.TwoDigit(ctx, fromDate, "second", "third")
+	counts, err = resultRepo.TwoDigit(ctx, fromDate, until, "second", "third")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-second-third: %w", err)
 	} else {
 		twoDigitCount.SecondThird = sumProbTwoDigit(counts, twoDigitCount.SecondThird, factors[3])
 	}
 
-	counts, err = resultRepo.TwoDigit(ctx, fromDate, "second", "fourth")
+	counts, err = resultRepo.TwoDigit(ctx, fromDate, until, "second", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-second-fourth: %w", err)
 	} else {
 		twoDigitCount.SecondFourth = sumProbTwoDigit(counts, twoDigitCount.SecondFourth, factors[4])
 	}
 
-	counts, err = resultRepo.TwoDigit(ctx, fromDate, "third", "fourth")
+	counts, err = resultRepo.TwoDigit(ctx, fromDate, until, "third", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by twoDigit-third-fourth: %w", err)
 	} else {
@@ -195,31 +227,31 @@ func sumProbTwoDigit(results []*model.TwoDigitCount, probAccumulated []float64,
 	return probAccumulated
 }
 
-func threeDigitFrequencies(ctx context.Context, fromDate time.Time, resultRepo repository.ResultRepository, threeDigitCount *model.ThreeDigitFrequency) error {
-	factors := []float64{1.0, 1.0, 5.0, 5.0}
+func threeDigitFrequencies(ctx context.Context, fromDate, until time.Time, resultRepo repository.ResultRepository, threeDigitCount *model.ThreeDigitFrequency) error {
+	factors := threeDigitFactors
 
-	counts, err := resultRepo.ThreeDigit(ctx, fromDate, "first", "second", "third")
+	counts, err := resultRepo.ThreeDigit(ctx, fromDate, until, "first", "second", "third")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by threeDigit-first-second-third: %w", err)
 	} else {
 		threeDigitCount.FirstSecondThird = sumProbThreeDigit(counts, threeDigitCount.FirstSecondThird, factors[0])
 	}
 
-	counts, err = resultRepo.ThreeDigit(ctx, fromDate, "first", "second", "fourth")
+	counts, err = resultRepo.ThreeDigit(ctx, fromDate, until, "first", "second", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by threeDigit-first-second-fourth: %w", err)
 	} else {
 		threeDigitCount.FirstSecondFourth = sumProbThreeDigit(counts, threeDigitCount.FirstSecondFourth, factors[1])
 	}
 
-	counts, err = resultRepo.ThreeDigit(ctx, fromDate, "first", "third", "fourth")
+	counts, err = resultRepo.ThreeDigit(ctx, fromDate, until, "first", "third", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by threeDigit-first-third-fourth: %w", err)
 	} else {
 		threeDigitCount.FirstThirdFourth = sumProbThreeDigit(counts, threeDigitCount.FirstThirdFourth, factors[2])
 	}
 
-	counts, err = resultRepo.ThreeDigit(ctx, fromDate, "second", "third", "fourth")
+	counts, err = resultRepo.ThreeDigit(ctx, fromDate, until, "second", "third", "fourth")
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by threeDigit-second-third-fourth: %w", err)
 	} else {
@@ -245,8 +277,8 @@ func sumProbThreeDigit(results []*model.ThreeDigitCount, probAccumulated []float
 	return probAccumulated
 }
 
-func fourDigitFrequencies(ctx context.Context, fromDate time.Time, resultRepo repository.ResultRepository, fourDigitCount *model.FourDigitFrequency) error {
-	counts, err := resultRepo.FourthDigit(ctx, fromDate)
+func fourDigitFrequencies(ctx context.Context, fromDate, until time.Time, resultRepo repository.ResultRepository, fourDigitCount *model.FourDigitFrequency) error {
+	counts, err := resultRepo.FourthDigit(ctx, fromDate, until)
 	if err != nil {
 		return fmt.Errorf("failed to get frecuencies by all digits: %w", err)
 	} else {
@@ -261,3 +293,70 @@ func fourDigitFrequencies(ctx context.Context, fromDate time.Time, resultRepo re
 
 	return err
 }
+
+// signFrequencies acumula la frecuencia de cada signo y la conjunta signo×dígito para cada posición.
+func signFrequencies(ctx context.Context, fromDate, until time.Time, resultRepo repository.ResultRepository, signCount *model.SignFrequency) error {
+	counts, err := resultRepo.Sign(ctx, fromDate, until)
+	if err != nil {
+		return fmt.Errorf("failed to get frecuencies by sign: %w", err)
+	}
+	signCount.Signs = sumProbSign(counts, signCount.Signs, 1.0)
+
+	positions := []struct {
+		column string
+		freq   []float64
+	}{
+		{"first", signCount.Position1},
+		{"second", signCount.Position2},
+		{"third", signCount.Position3},
+		{"fourth", signCount.Position4},
+	}
+
+	for _, position := range positions {
+		counts, err := resultRepo.SignDigit(ctx, fromDate, until, position.column)
+		if err != nil {
+			return fmt.Errorf("failed to get frecuencies by sign-%s: %w", position.column, err)
+		}
+		sumProbSignDigit(counts, position.freq, 1.0)
+	}
+
+	return nil
+}
+
+func sumProbSign(results []*model.SignCount, probAccumulated []float64, factor float64) []float64 {
+	totalResults := 0
+
+	// Los signos desconocidos ("Z") no cuentan para el total
+	for _, p := range results {
+		if model.SignIndex(p.Sign) >= 0 {
+			totalResults += p.Count
+		}
+	}
+
+	for _, p := range results {
+		if idx := model.SignIndex(p.Sign); idx >= 0 {
+			probAccumulated[idx] += (float64(p.Count) / float64(totalResults)) * factor
+		}
+	}
+
+	return probAccumulated
+}
+
+func sumProbSignDigit(results []*model.SignDigitCount, probAccumulated []float64, factor float64) []float64 {
+	totalResults := 0
+	weighting := 10.0 // mismo orden de magnitud que las combinaciones de dos dígitos
+
+	for _, p := range results {
+		if model.SignIndex(p.Sign) >= 0 {
+			totalResults += p.Count
+		}
+	}
+
+	for _, p := range results {
+		if idx := model.SignIndex(p.Sign); idx >= 0 {
+			probAccumulated[idx*10+p.Digit] += (float64(p.Count) / float64(totalResults)) * factor * weighting
+		}
+	}
+
+	return probAccumulated
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestSumProbSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []*model.SignCount
+		want   map[string]float64
+	}{
+		{"known signs", []*model.SignCount{{Sign: "A", Count: 1}, {Sign: "C", Count: 3}},
+			map[string]float64{"A": 0.25, "C": 0.75}},
+		{"unknown sign is left out of the total", []*model.SignCount{{Sign: "A", Count: 1}, {Sign: "B", Count: 1}, {Sign: "Z", Count: 8}},
+			map[string]float64{"A": 0.5, "B": 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sumProbSign(tt.counts, make([]float64, len(model.Signs)), 1.0)
+			if len(got) != len(model.Signs) {
+				t.Fatalf("got %d signs, want %d", len(got), len(model.Signs))
+			}
+			for i, sign := range model.Signs {
+				if math.Abs(got[i]-tt.want[sign]) > 1e-12 {
+					t.Errorf("sign %s = %v, want %v", sign, got[i], tt.want[sign])
+				}
+			}
+		})
+	}
+}
+
+func TestSumProbSignDigit(t *testing.T) {
+	counts := []*model.SignDigitCount{
+		{Sign: "A", Digit: 3, Count: 1},
+		{Sign: "L", Digit: 9, Count: 3},
+		{Sign: "Z", Digit: 0, Count: 100},
+	}
+	got := sumProbSignDigit(counts, make([]float64, len(model.Signs)*10), 1.0)
+
+	// El peso 10 pone las frecuencias en el orden de las de dos dígitos
+	want := map[int]float64{0*10 + 3: 2.5, 11*10 + 9: 7.5}
+	for i, v := range got {
+		if math.Abs(v-want[i]) > 1e-12 {
+			t.Errorf("cell %d = %v, want %v", i, v, want[i])
+		}
+	}
+}
+
+func TestBestCombinations(t *testing.T) {
+	f := newFrequencyData()
+	for i := range f.SignFreq.Signs {
+		f.SignFreq.Signs[i] = float64(i) / 100
+	}
+	f.SignFreq.Position1[2*10+1] = 0.5 // C con 1 en la primera posición
+
+	p := &processorService{}
+	numbers := []int{1234, 5678}
+	scores := []float64{1, 2}
+
+	tests := []struct {
+		mode  string
+		first model.NumberSign
+	}{
+		{ModeCold, model.NumberSign{Number: 1234, Sign: "A", Score: 1}},
+		{ModeHot, model.NumberSign{Number: 5678, Sign: "L", Score: 2.11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			best := p.bestCombinations(numbers, scores, f, tt.mode)
+			if len(best) != len(numbers) {
+				t.Fatalf("got %d combinations, want %d", len(best), len(numbers))
+			}
+			if best[0].Number != tt.first.Number || best[0].Sign != tt.first.Sign || math.Abs(best[0].Score-tt.first.Score) > 1e-12 {
+				t.Errorf("first combination = %+v, want %+v", best[0], tt.first)
+			}
+
+			keys := make([]float64, len(best))
+			for i, c := range best {
+				keys[i] = c.Score
+			}
+			keys = modeKeys(keys, tt.mode)
+			for i := 1; i < len(keys); i++ {
+				if keys[i] < keys[i-1] {
+					t.Errorf("combinations are not ordered for %s: %+v", tt.mode, best)
+				}
+			}
+		})
+	}
+
+	// El score conjunto suma el del número, el del signo y el de cada posición
+	if got := p.calculateCombinationScore(1234, 1, 2, f); math.Abs(got-1.52) > 1e-12 {
+		t.Errorf("combination score of 1234 C = %v, want 1.52", got)
+	}
+}
+
+func TestRankSigns(t *testing.T) {
+	f := newFrequencyData()
+	copy(f.SignFreq.Signs, []float64{0.3, 0.1, 0.2})
+
+	p := &processorService{}
+	signs, scores := p.rankSigns(f, ModeHot)
+	if signs[0] != "A" || signs[1] != "C" || signs[2] != "B" || scores[0] != 0.3 {
+		t.Errorf("hot signs = %v %v, want A, C, B first", signs[:3], scores[:3])
+	}
+
+	signs, _ = p.rankSigns(f, ModeCold)
+	if signs[len(signs)-1] != "A" {
+		t.Errorf("cold signs = %v, want A last", signs)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"math"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// digitPositions son las columnas de los dígitos en el orden de utils.Digits.
+var digitPositions = []string{"first", "second", "third", "fourth"}
+
+// alignedDraw son los resultados de las dos loterías en un mismo día.
+type alignedDraw struct {
+	a, b *model.Result
+}
+
+// alignDraws empareja por fecha los sorteos de a y b, ya ordenados; si una lotería tiene más de un
+// resultado el mismo día se usa el primero.
+func alignDraws(a, b []draw) []alignedDraw {
+	byDate := make(map[string]*model.Result, len(b))
+	for _, dr := range b {
+		key := dr.date.Format(utils.DateLayout)
+		if _, ok := byDate[key]; !ok {
+			byDate[key] = dr.result
+		}
+	}
+
+	aligned := make([]alignedDraw, 0, len(a))
+	seen := make(map[string]bool, len(a))
+	for _, dr := range a {
+		key := dr.date.Format(utils.DateLayout)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if other, ok := byDate[key]; ok {
+			aligned = append(aligned, alignedDraw{a: dr.result, b: other})
+		}
+	}
+	return aligned
+}
+
+// buildCorrelationReport calcula la co-ocurrencia de dígitos entre todas las parejas de posiciones
+// y las coincidencias de número y signo de los sorteos alineados.
+func buildCorrelationReport(aligned []alignedDraw) *model.CorrelationReport {
+	report := &model.CorrelationReport{AlignedDraws: len(aligned)}
+
+	digitsA := make([][4]int, len(aligned))
+	digitsB := make([][4]int, len(aligned))
+	for i, pair := range aligned {
+		digitsA[i] = resultDigits(pair.a)
+		digitsB[i] = resultDigits(pair.b)
+	}
+
+	comparisons := len(digitPositions) * len(digitPositions)
+	for posA, nameA := range digitPositions {
+		for posB, nameB := range digitPositions {
+			report.Positions = append(report.Positions,
+				positionCorrelation(digitsA, digitsB, posA, posB, nameA, nameB, comparisons))
+		}
+	}
+
+	sameNumber := 0
+	for i := range aligned {
+		if digitsA[i] == digitsB[i] {
+			sameNumber++
+		}
+	}
+	report.SameNumber = matchTest(sameNumber, len(aligned), 1.0/10000)
+	report.SameSign = sameSignTest(aligned)
+
+	return report
+}
+
+func positionCorrelation(digitsA, digitsB [][4]int, posA, posB int, nameA, nameB string, comparisons int) model.PositionCorrelation {
+	table := make([][]int, 10)
+	for i := range table {
+		table[i] = make([]int, 10)
+	}
+
+	x := make([]float64, len(digitsA))
+	y := make([]float64, len(digitsB))
+	same := 0
+	for i := range digitsA {
+		da, db := digitsA[i][posA], digitsB[i][posB]
+		table[da][db]++
+		x[i], y[i] = float64(da), float64(db)
+		if da == db {
+			same++
+		}
+	}
+
+	stat, df := utils.ChiSquareIndependence(table)
+	correlation := model.PositionCorrelation{
+		PositionA:        nameA,
+		PositionB:        nameB,
+		CoOccurrence:     table,
+		ChiSquare:        stat,
+		DegreesOfFreedom: df,
+		PValue:           1,
+		AdjustedPValue:   1,
+		Pearson:          utils.Pearson(x, y),
+	}
+	if df > 0 {
+		correlation.PValue = utils.ChiSquarePValue(stat, df)
+		correlation.AdjustedPValue = math.Min(1, correlation.PValue*float64(comparisons))
+	}
+	correlation.PearsonPValue = utils.PearsonPValue(correlation.Pearson, len(x))
+
+	// Bajo independencia la probabilidad de coincidir sale de las frecuencias de cada posición
+	correlation.SameDigit = matchTest(same, len(digitsA), independentMatchShare(table))
+
+	return correlation
+}
+
+// sameSignTest compara las coincidencias de signo con las esperadas según la frecuencia de signos
+// de cada lotería; los días con algún signo desconocido no cuentan.
+func sameSignTest(aligned []alignedDraw) model.MatchTest {
+	table := make([][]int, len(model.Signs))
+	for i := range table {
+		table[i] = make([]int, len(model.Signs))
+	}
+
+	trials, matches := 0, 0
+	for _, pair := range aligned {
+		signA, signB := model.SignIndex(pair.a.Sign), model.SignIndex(pair.b.Sign)
+		if signA < 0 || signB < 0 {
+			continue
+		}
+		table[signA][signB]++
+		trials++
+		if signA == signB {
+			matches++
+		}
+	}
+
+	return matchTest(matches, trials, independentMatchShare(table))
+}
+
+// independentMatchShare es la probabilidad de que coincidan los valores de las dos loterías si son
+// independientes: la suma de los productos de sus frecuencias marginales.
+func independentMatchShare(table [][]int) float64 {
+	total := 0
+	rowTotals := make([]int, len(table))
+	colTotals := make([]int, len(table))
+	for i, row := range table {
+		for j, count := range row {
+			rowTotals[i] += count
+			colTotals[j] += count
+			total += count
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	share := 0.0
+	for i := range table {
+		share += float64(rowTotals[i]) / float64(total) * float64(colTotals[i]) / float64(total)
+	}
+	return share
+}
+
+func matchTest(matches, trials int, expected float64) model.MatchTest {
+	test := model.MatchTest{
+		Trials:        trials,
+		Matches:       matches,
+		ExpectedShare: expected,
+		PValue:        utils.BinomialPValue(matches, trials, expected),
+	}
+	if trials > 0 {
+		test.ObservedShare = float64(matches) / float64(trials)
+	}
+	return test
+}
+
+func resultDigits(r *model.Result) [4]int {
+	return [4]int{r.First, r.Second, r.Third, r.Fourth}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAlignDraws(t *testing.T) {
+	a := testDraws("2024-01-01", "2024-01-02", "2024-01-02", "2024-01-04")
+	b := testDraws("2024-01-02", "2024-01-02", "2024-01-03", "2024-01-04")
+
+	aligned := alignDraws(a, b)
+	want := []alignedDraw{{a[1].result, b[0].result}, {a[3].result, b[3].result}}
+	if len(aligned) != len(want) {
+		t.Fatalf("%d aligned days, want %d", len(aligned), len(want))
+	}
+	for i := range want {
+		if aligned[i] != want[i] {
+			t.Errorf("day %d aligned %v with %v, want the first result of each lottery", i, aligned[i].a.Date, aligned[i].b.Date)
+		}
+	}
+
+	if got := alignDraws(a, nil); len(got) != 0 {
+		t.Errorf("%d days aligned with an empty lottery", len(got))
+	}
+}
+
+func TestIndependentMatchShare(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]int
+		want  float64
+	}{
+		{"empty", [][]int{{0, 0}, {0, 0}}, 0},
+		{"uniform", [][]int{{1, 1}, {1, 1}}, 0.5},
+		// Sólo importan las marginales, no la diagonal observada
+		{"diagonal", [][]int{{2, 0}, {0, 2}}, 0.5},
+		{"skewed", [][]int{{3, 1}, {0, 0}}, 0.75},
+		{"ten values", uniformTable(10), 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := independentMatchShare(tt.table); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("independentMatchShare = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func uniformTable(size int) [][]int {
+	table := make([][]int, size)
+	for i := range table {
+		table[i] = make([]int, size)
+		for j := range table[i] {
+			table[i][j] = 1
+		}
+	}
+	return table
+}
+
+func TestBuildCorrelationReport(t *testing.T) {
+	history := testHistory(200, 8)
+	draws, err := newDraws(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Una lotería contra sí misma: todas las coincidencias y dependencia total en la misma posición
+	report := buildCorrelationReport(alignDraws(draws, draws))
+	if report.AlignedDraws != len(history) || len(report.Positions) != 16 {
+		t.Fatalf("%d aligned draws and %d position pairs", report.AlignedDraws, len(report.Positions))
+	}
+	if report.SameNumber.Matches != len(history) || report.SameNumber.PValue > 1e-10 {
+		t.Errorf("same number: %+v", report.SameNumber)
+	}
+	if report.SameSign.Matches != report.SameSign.Trials || report.SameSign.PValue > 1e-10 {
+		t.Errorf("same sign: %+v", report.SameSign)
+	}
+
+	for _, position := range report.Positions {
+		if position.PositionA != position.PositionB {
+			continue
+		}
+		if position.SameDigit.ObservedShare != 1 || math.Abs(position.Pearson-1) > 1e-9 {
+			t.Errorf("%s: same digit share %v, pearson %v", position.PositionA, position.SameDigit.ObservedShare, position.Pearson)
+		}
+		if position.DegreesOfFreedom != 81 || position.AdjustedPValue > 1e-10 {
+			t.Errorf("%s: %d df, adjusted p-value %v", position.PositionA, position.DegreesOfFreedom, position.AdjustedPValue)
+		}
+	}
+
+	empty := buildCorrelationReport(nil)
+	if empty.AlignedDraws != 0 || empty.SameNumber.PValue != 1 || empty.Positions[0].PValue != 1 {
+		t.Errorf("empty report: %+v", empty)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+const (
+	defaultPermutations = 200
+	// maxPermutations limita la prueba: cada permutación recalcula las 18 informaciones mutuas
+	maxPermutations = 2000
+)
+
+// positionPair y positionTriple son las combinaciones de posiciones que usa el scoring.
+type positionPair struct {
+	name string
+	a, b int
+}
+
+type positionTriple struct {
+	name      string
+	positions [3]int
+}
+
+var positionPairs = []positionPair{
+	{"first_second", 0, 1},
+	{"first_third", 0, 2},
+	{"first_fourth", 0, 3},
+	{"second_third", 1, 2},
+	{"second_fourth", 1, 3},
+	{"third_fourth", 2, 3},
+}
+
+var positionTriples = []positionTriple{
+	{"first_second_third", [3]int{0, 1, 2}},
+	{"first_second_fourth", [3]int{0, 1, 3}},
+	{"first_third_fourth", [3]int{0, 2, 3}},
+	{"second_third_fourth", [3]int{1, 2, 3}},
+}
+
+// buildDependencyReport calcula la información mutua de cada pareja de posiciones y la condicional
+// de cada pareja dentro de cada trío; la significancia sale de permutar una de las posiciones
+// (dentro de cada valor de la condicionante en el caso condicional).
+func buildDependencyReport(ctx context.Context, draws []draw, params model.DependencyParams) (*model.DependencyReport, error) {
+	if params.Draws > 0 && len(draws) > params.Draws {
+		draws = draws[len(draws)-params.Draws:]
+	}
+
+	columns := make([][]int, len(digitPositions))
+	for position := range columns {
+		columns[position] = make([]int, len(draws))
+	}
+	for i, dr := range draws {
+		for position, digit := range resultDigits(dr.result) {
+			columns[position][i] = digit
+		}
+	}
+
+	rng := rand.New(rand.NewSource(params.Seed))
+	report := &model.DependencyReport{TotalDraws: len(draws), Params: params}
+
+	for _, pair := range positionPairs {
+		x, y := columns[pair.a], columns[pair.b]
+		table := digitTable(x, y)
+		stat, df := utils.ChiSquareIndependence(table)
+
+		dependency := model.PairDependency{
+			Positions:         pair.name,
+			MutualInformation: utils.MutualInformation(table),
+			CramersV:          utils.CramersV(stat, len(x), 10, 10),
+			ChiSquare:         stat,
+			DegreesOfFreedom:  df,
+			PValue:            1,
+		}
+		if df > 0 {
+			dependency.PValue = utils.ChiSquarePValue(stat, df)
+		}
+
+		var err error
+		dependency.PermutationMean, dependency.PermutationPValue, err = permutationTest(ctx,
+			dependency.MutualInformation, y, params.Permutations, rng, nil,
+			func(shuffled []int) float64 { return utils.MutualInformation(digitTable(x, shuffled)) })
+		if err != nil {
+			return nil, err
+		}
+
+		report.Pairs = append(report.Pairs, dependency)
+	}
+
+	for _, triple := range positionTriples {
+		for given := range triple.positions {
+			// La pareja son las otras dos posiciones, en su orden
+			pair := make([]int, 0, 2)
+			for i, position := range triple.positions {
+				if i != given {
+					pair = append(pair, position)
+				}
+			}
+			x, y, z := columns[pair[0]], columns[pair[1]], columns[triple.positions[given]]
+
+			dependency := model.ConditionalDependency{
+				Triple:            triple.name,
+				Pair:              digitPositions[pair[0]] + "_" + digitPositions[pair[1]],
+				Given:             digitPositions[triple.positions[given]],
+				MutualInformation: conditionalMutualInformation(x, y, z),
+			}
+
+			var err error
+			dependency.PermutationMean, dependency.PermutationPValue, err = permutationTest(ctx,
+				dependency.MutualInformation, y, params.Permutations, rng, strata(z),
+				func(shuffled []int) float64 { return conditionalMutualInformation(x, shuffled, z) })
+			if err != nil {
+				return nil, err
+			}
+
+			report.Conditionals = append(report.Conditionals, dependency)
+		}
+	}
+
+	return report, nil
+}
+
+// digitTable cruza dos columnas de dígitos en una tabla 10x10.
+func digitTable(x, y []int) [][]int {
+	table := make([][]int, 10)
+	for i := range table {
+		table[i] = make([]int, 10)
+	}
+	for i := range x {
+		table[x[i]][y[i]]++
+	}
+	return table
+}
+
+// conditionalMutualInformation es I(X;Y|Z) en bits: la información mutua dentro de cada valor de Z
+// ponderada por su frecuencia.
+func conditionalMutualInformation(x, y, z []int) float64 {
+	tables := make([][][]int, 10)
+	counts := make([]int, 10)
+	for i := range x {
+		if tables[z[i]] == nil {
+			tables[z[i]] = digitTable(nil, nil)
+		}
+		tables[z[i]][x[i]][y[i]]++
+		counts[z[i]]++
+	}
+
+	mi := 0.0
+	for value, table := range tables {
+		if table == nil {
+			continue
+		}
+		mi += float64(counts[value]) / float64(len(x)) * utils.MutualInformation(table)
+	}
+	return mi
+}
+
+// strata agrupa los índices por el valor de la columna.
+func strata(column []int) [][]int {
+	groups := make([][]int, 10)
+	for i, value := range column {
+		groups[value] = append(groups[value], i)
+	}
+	return groups
+}
+
+// permutationTest baraja values (dentro de cada estrato si los hay) y devuelve la media del
+// estadístico permutado y el p-valor de observar uno al menos tan grande como observed. Se corta
+// entre permutaciones si se cancela ctx.
+func permutationTest(ctx context.Context, observed float64, values []int, permutations int, rng *rand.Rand, groups [][]int, statistic func([]int) float64) (float64, float64, error) {
+	if permutations <= 0 {
+		return 0, 1, nil
+	}
+
+	shuffled := make([]int, len(values))
+	sum, extreme := 0.0, 0
+	for p := 0; p < permutations; p++ {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+
+		copy(shuffled, values)
+		if groups == nil {
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		} else {
+			for _, group := range groups {
+				rng.Shuffle(len(group), func(i, j int) {
+					shuffled[group[i]], shuffled[group[j]] = shuffled[group[j]], shuffled[group[i]]
+				})
+			}
+		}
+
+		value := statistic(shuffled)
+		sum += value
+		if value >= observed {
+			extreme++
+		}
+	}
+
+	return sum / float64(permutations), float64(1+extreme) / float64(1+permutations), nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+// xorColumns cubre todas las parejas (x, z) repeat veces con y = (x + z) % 10: X e Y son
+// independientes, pero dado Z cada una determina la otra.
+func xorColumns(repeat int) (x, y, z []int) {
+	for r := 0; r < repeat; r++ {
+		for a := 0; a < 10; a++ {
+			for c := 0; c < 10; c++ {
+				x, y, z = append(x, a), append(y, (a+c)%10), append(z, c)
+			}
+		}
+	}
+	return x, y, z
+}
+
+func TestConditionalMutualInformation(t *testing.T) {
+	x, y, z := xorColumns(1)
+	constant := make([]int, len(x))
+
+	tests := []struct {
+		name    string
+		x, y, z []int
+		want    float64
+	}{
+		{"determined given z", x, y, z, math.Log2(10)},
+		{"identical columns, constant z", x, x, constant, math.Log2(10)},
+		{"independent columns, constant z", x, z, constant, 0},
+		{"z explains everything", z, z, z, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditionalMutualInformation(tt.x, tt.y, tt.z); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("I(X;Y|Z) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermutationTest(t *testing.T) {
+	ctx := context.Background()
+	x, y, z := xorColumns(2)
+	groups := strata(z)
+
+	// Barajar dentro de cada estrato conserva los valores de y de cada valor de z
+	byStratum := func(values []int) [][]int {
+		sorted := make([][]int, len(groups))
+		for value, group := range groups {
+			for _, i := range group {
+				sorted[value] = append(sorted[value], values[i])
+			}
+			sort.Ints(sorted[value])
+		}
+		return sorted
+	}
+	want := byStratum(y)
+	preserved := func(shuffled []int) float64 {
+		for value, values := range byStratum(shuffled) {
+			for i := range values {
+				if values[i] != want[value][i] {
+					return 0
+				}
+			}
+		}
+		return 1
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	if mean, _, err := permutationTest(ctx, 0, y, 50, rng, groups, preserved); err != nil || mean != 1 {
+		t.Errorf("stratified shuffle kept the strata in a share %v of the permutations (%v)", mean, err)
+	}
+
+	observed := conditionalMutualInformation(x, y, z)
+	mean, pValue, err := permutationTest(ctx, observed, y, 99, rng, groups,
+		func(shuffled []int) float64 { return conditionalMutualInformation(x, shuffled, z) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pValue != 0.01 || mean >= observed {
+		t.Errorf("dependent columns: permutation mean %v, p-value %v", mean, pValue)
+	}
+
+	if _, pValue, _ := permutationTest(ctx, observed, y, 0, rng, groups, preserved); pValue != 1 {
+		t.Errorf("no permutations gave p-value %v, want 1", pValue)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := permutationTest(canceled, observed, y, 10, rng, nil, preserved); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled permutation test returned %v", err)
+	}
+}
+
+func TestBuildDependencyReport(t *testing.T) {
+	x, y, z := xorColumns(3)
+	history := testHistory(len(x), 10)
+	for i, result := range history {
+		result.First, result.Second, result.Third = x[i], z[i], y[i]
+	}
+	draws, err := newDraws(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := model.DependencyParams{Permutations: 49, Seed: 3}
+	report, err := buildDependencyReport(context.Background(), draws, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalDraws != len(x) || len(report.Pairs) != 6 || len(report.Conditionals) != 12 {
+		t.Fatalf("%d draws, %d pairs, %d conditionals", report.TotalDraws, len(report.Pairs), len(report.Conditionals))
+	}
+
+	for _, pair := range report.Pairs {
+		if pair.Positions == "first_third" && (pair.MutualInformation > 1e-9 || pair.PValue < 0.99) {
+			t.Errorf("first_third looks dependent: %+v", pair)
+		}
+	}
+	for _, conditional := range report.Conditionals {
+		if conditional.Pair == "first_third" && conditional.Given == "second" {
+			if math.Abs(conditional.MutualInformation-math.Log2(10)) > 1e-9 || conditional.PermutationPValue != 0.02 {
+				t.Errorf("first_third given second: %+v", conditional)
+			}
+		}
+	}
+
+	params.Draws = 100
+	if report, _ := buildDependencyReport(context.Background(), draws, params); report.TotalDraws != 100 {
+		t.Errorf("draws limit kept %d draws", report.TotalDraws)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := buildDependencyReport(canceled, draws, params); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled report returned %v", err)
+	}
+}
+
+func TestDependenciesLimits(t *testing.T) {
+	p := newTestProcessor(newFakeResultRepo(1, testHistory(20, 11)...))
+	start, end := testDate("2020-01-01"), testDate("2020-12-31")
+
+	tests := []struct {
+		permutations int
+		want         int
+	}{
+		{0, defaultPermutations},
+		{1000000, maxPermutations},
+	}
+	for _, tt := range tests {
+		report, err := p.Dependencies(context.Background(), start, end, model.DependencyParams{Permutations: tt.permutations})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Params.Permutations != tt.want {
+			t.Errorf("%d permutations ran as %d, want %d", tt.permutations, report.Params.Permutations, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// Normalizaciones con las que se llevan los scores de cada miembro a una escala común
+const (
+	NormalizationRank   = "rank"
+	NormalizationZScore = "zscore"
+)
+
+const (
+	defaultFitDraws = 30
+	// maxFitDraws limita el ajuste: cada sorteo vuelve a puntuar todos los miembros en cada paso
+	maxFitDraws = 200
+)
+
+// defaultEnsembleMembers son las estrategias que combina el ensemble.
+var defaultEnsembleMembers = []string{StrategySingle, StrategyPair, StrategyTriple, StrategyFull}
+
+// ensembleStrategy combina varias estrategias normalizando sus scores y sumándolos con pesos
+// ajustados sobre los últimos sorteos del histórico. Cada modo de selección ordena al revés o por
+// distancia, así que los pesos se ajustan por separado para cada uno.
+type ensembleStrategy struct {
+	members       []Strategy
+	normalization string
+	fitDraws      int
+
+	// Estado del último scoring, usado para los scores de cada modo y el reporte de contribuciones
+	weights    map[string][]float64
+	normalized [][]float64
+	fitScores  map[string]float64
+	fitted     int
+}
+
+func newEnsembleStrategy(params model.AnalysisParams) (*ensembleStrategy, error) {
+	e := &ensembleStrategy{
+		normalization: params.Normalization,
+		fitDraws:      params.FitDraws,
+	}
+
+	if e.normalization == "" {
+		e.normalization = NormalizationRank
+	}
+	if e.normalization != NormalizationRank && e.normalization != NormalizationZScore {
+		return nil, fmt.Errorf("unknown normalization: %s", e.normalization)
+	}
+	if e.fitDraws <= 0 {
+		e.fitDraws = defaultFitDraws
+	}
+	if e.fitDraws > maxFitDraws {
+		e.fitDraws = maxFitDraws
+	}
+
+	for _, name := range defaultEnsembleMembers {
+		member, err := NewStrategy(model.AnalysisParams{Strategy: name})
+		if err != nil {
+			return nil, err
+		}
+		e.members = append(e.members, member)
+	}
+
+	return e, nil
+}
+
+func (e *ensembleStrategy) Name() string { return StrategyEnsemble }
+
+// Scores devuelve la combinación con los pesos del modo en frío; los demás modos usan modeScores.
+func (e *ensembleStrategy) Scores(ctx context.Context, in *StrategyInput) ([]float64, error) {
+	if err := e.fit(ctx, in); err != nil {
+		return nil, fmt.Errorf("failed to fit ensemble weights: %w", err)
+	}
+
+	normalized, err := e.memberScores(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	e.normalized = normalized
+	return e.modeScores(ModeCold), nil
+}
+
+// modeScores combina los scores del último scoring con los pesos ajustados para el modo.
+func (e *ensembleStrategy) modeScores(mode string) []float64 {
+	return blend(e.normalized, e.modeWeights(mode))
+}
+
+func (e *ensembleStrategy) modeWeights(mode string) []float64 {
+	if weights, ok := e.weights[mode]; ok {
+		return weights
+	}
+	return e.weights[ModeCold]
+}
+
+// memberScores puntúa con cada miembro y normaliza sus scores.
+func (e *ensembleStrategy) memberScores(ctx context.Context, in *StrategyInput) ([][]float64, error) {
+	normalized := make([][]float64, len(e.members))
+	for i, member := range e.members {
+		scores, err := member.Scores(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %s failed: %w", member.Name(), err)
+		}
+		normalized[i] = normalizeScores(scores, e.normalization)
+	}
+	return normalized, nil
+}
+
+// fit ajusta los pesos de cada modo con los últimos fitDraws sorteos: para cada uno recalcula las
+// frecuencias con los sorteos anteriores y busca los pesos que dejan al número ganador lo más arriba
+// posible en el orden del modo. Sin histórico suficiente se usan pesos iguales.
+func (e *ensembleStrategy) fit(ctx context.Context, in *StrategyInput) error {
+	equal := make([]float64, len(e.members))
+	for i := range equal {
+		equal[i] = 1.0 / float64(len(equal))
+	}
+	e.weights = make(map[string][]float64, len(SelectionModes))
+	e.fitScores = make(map[string]float64, len(SelectionModes))
+	e.fitted = 0
+	for _, mode := range SelectionModes {
+		e.weights[mode] = equal
+	}
+
+	draws, err := newDraws(in.History)
+	if err != nil {
+		return err
+	}
+	if len(draws) <= e.fitDraws {
+		return nil
+	}
+
+	type sample struct {
+		actual     int
+		normalized [][]float64
+	}
+
+	samples := make([]sample, 0, e.fitDraws)
+	for _, test := range draws[len(draws)-e.fitDraws:] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Sólo los sorteos anteriores a la fecha evaluada
+		train := sort.Search(len(draws), func(i int) bool { return !draws[i].date.Before(test.date) })
+		frequencies, _ := frequenciesFromDraws(draws[:train], test.date)
+
+		normalized, err := e.memberScores(ctx, &StrategyInput{Frequencies: frequencies, AsOf: test.date})
+		if err != nil {
+			return err
+		}
+
+		r := test.result
+		samples = append(samples, sample{
+			actual:     r.First*1000 + r.Second*100 + r.Third*10 + r.Fourth,
+			normalized: normalized,
+		})
+	}
+
+	for _, mode := range SelectionModes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e.weights[mode], e.fitScores[mode] = fitWeights(equal, func(w []float64) float64 {
+			// Percentil medio del número ganador en el orden del modo: menor es mejor
+			total := 0.0
+			for _, s := range samples {
+				total += winnerPercentile(modeKeys(blend(s.normalized, w), mode), s.actual)
+			}
+			return total / float64(len(samples))
+		})
+	}
+
+	e.fitted = len(samples)
+	return nil
+}
+
+// fitWeights busca por coordenadas, con pasos cada vez más chicos, los pesos que minimizan objective.
+func fitWeights(initial []float64, objective func(w []float64) float64) ([]float64, float64) {
+	weights := initial
+	best := objective(weights)
+	for _, step := range []float64{0.2, 0.1, 0.05, 0.02} {
+		for iteration, improved := 0, true; improved && iteration < 100; iteration++ {
+			improved = false
+			for m := range weights {
+				for _, delta := range []float64{step, -step} {
+					candidate := make([]float64, len(weights))
+					copy(candidate, weights)
+					candidate[m] = math.Max(0, candidate[m]+delta)
+					if !normalizeWeights(candidate) {
+						continue
+					}
+					if value := objective(candidate); value < best-1e-12 {
+						weights, best, improved = candidate, value, true
+					}
+				}
+			}
+		}
+	}
+	return weights, best
+}
+
+// winnerPercentile es la fracción de números con clave menor que la del ganador.
+func winnerPercentile(keys []float64, actual int) float64 {
+	below := 0
+	for _, v := range keys {
+		if v < keys[actual] {
+			below++
+		}
+	}
+	return float64(below) / float64(len(keys))
+}
+
+// report arma los pesos del modo y la contribución de cada miembro para los números indicados.
+func (e *ensembleStrategy) report(numbers []int, mode string) *model.EnsembleReport {
+	weights := e.modeWeights(mode)
+	report := &model.EnsembleReport{
+		Mode:          mode,
+		Normalization: e.normalization,
+		Weights:       make(map[string]float64, len(e.members)),
+		FitDraws:      e.fitted,
+		FitScore:      e.fitScores[mode],
+		Contributions: make([]model.EnsembleContribution, 0, len(numbers)),
+	}
+
+	for i, member := range e.members {
+		report.Weights[member.Name()] = weights[i]
+	}
+
+	for _, number := range numbers {
+		contribution := model.EnsembleContribution{Number: number}
+		for i, member := range e.members {
+			value := e.normalized[i][number]
+			contribution.Members = append(contribution.Members, model.MemberContribution{
+				Strategy:     member.Name(),
+				Weight:       weights[i],
+				Normalized:   value,
+				Contribution: weights[i] * value,
+			})
+			contribution.Total += weights[i] * value
+		}
+		report.Contributions = append(report.Contributions, contribution)
+	}
+
+	return report
+}
+
+// blend suma los scores normalizados de cada miembro multiplicados por su peso.
+func blend(normalized [][]float64, weights []float64) []float64 {
+	blended := make([]float64, len(normalized[0]))
+	for m, scores := range normalized {
+		for number, v := range scores {
+			blended[number] += weights[m] * v
+		}
+	}
+	return blended
+}
+
+// normalizeWeights deja los pesos sumando 1; devuelve false si son todos cero.
+func normalizeWeights(weights []float64) bool {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return false
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return true
+}
+
+// normalizeScores lleva los scores a percentil de ranking (0 = mejor, 1 = peor, empates con el rango
+// medio) o a z-score.
+func normalizeScores(scores []float64, normalization string) []float64 {
+	normalized := make([]float64, len(scores))
+
+	if normalization == NormalizationZScore {
+		mean, std := utils.MeanStd(scores)
+		if std == 0 {
+			return normalized
+		}
+		for i, v := range scores {
+			normalized[i] = (v - mean) / std
+		}
+		return normalized
+	}
+
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return scores[indices[i]] < scores[indices[j]]
+	})
+
+	last := float64(len(scores) - 1)
+	for start := 0; start < len(indices); {
+		end := start
+		for end+1 < len(indices) && scores[indices[end+1]] == scores[indices[start]] {
+			end++
+		}
+		rank := float64(start+end) / 2 / last
+		for k := start; k <= end; k++ {
+			normalized[indices[k]] = rank
+		}
+		start = end + 1
+	}
+
+	return normalized
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeScores(t *testing.T) {
+	tests := []struct {
+		name          string
+		scores        []float64
+		normalization string
+		want          []float64
+	}{
+		{"rank ascending", []float64{3, 1, 2}, NormalizationRank, []float64{1, 0, 0.5}},
+		{"rank ties share the mean rank", []float64{5, 1, 5, 0, 9}, NormalizationRank, []float64{0.625, 0.25, 0.625, 0, 1}},
+		{"rank all equal", []float64{2, 2, 2}, NormalizationRank, []float64{0.5, 0.5, 0.5}},
+		{"zscore", []float64{1, 2, 3}, NormalizationZScore, []float64{-1.224744871391589, 0, 1.224744871391589}},
+		{"zscore without spread", []float64{4, 4}, NormalizationZScore, []float64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeScores(tt.scores, tt.normalization)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d values, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("value %d = %v, want %v (all %v)", i, got[i], tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	weights := []float64{1, 3, 0}
+	if !normalizeWeights(weights) {
+		t.Fatal("normalizeWeights returned false for positive weights")
+	}
+	want := []float64{0.25, 0.75, 0}
+	for i := range want {
+		if weights[i] != want[i] {
+			t.Errorf("weights = %v, want %v", weights, want)
+			break
+		}
+	}
+
+	if normalizeWeights([]float64{0, 0}) {
+		t.Error("normalizeWeights accepted all-zero weights")
+	}
+}
+
+func TestWinnerPercentileByMode(t *testing.T) {
+	// El ganador (índice 0) tiene el score más alto: es el peor en frío y el mejor en caliente
+	scores := []float64{9, 1, 2, 3}
+	tests := []struct {
+		mode string
+		want float64
+	}{
+		{ModeCold, 0.75},
+		{ModeHot, 0},
+		{ModeDistance, 0.75},
+	}
+
+	for _, tt := range tests {
+		if got := winnerPercentile(modeKeys(scores, tt.mode), 0); got != tt.want {
+			t.Errorf("%s: percentile = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestFitWeights(t *testing.T) {
+	// Con pesos iguales el ganador (índice 0) queda último en frío; el primer miembro lo pone primero
+	normalized := [][]float64{
+		{0, 0.6, 0.6},
+		{1, 0, 0},
+	}
+	objective := func(w []float64) float64 {
+		return winnerPercentile(modeKeys(blend(normalized, w), ModeCold), 0)
+	}
+
+	equal := []float64{0.5, 0.5}
+	if start := objective(equal); start == 0 {
+		t.Fatalf("objective with equal weights = %v, the test needs room to improve", start)
+	}
+
+	weights, best := fitWeights(equal, objective)
+	if best != 0 {
+		t.Errorf("objective = %v, want 0", best)
+	}
+	if weights[0] <= weights[1] {
+		t.Errorf("weights = %v, want the first member to dominate", weights)
+	}
+	if sum := weights[0] + weights[1]; math.Abs(sum-1) > 1e-9 {
+		t.Errorf("weights sum %v, want 1", sum)
+	}
+	if equal[0] != 0.5 || equal[1] != 0.5 {
+		t.Errorf("fitWeights modified the initial weights: %v", equal)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+
+	"lottery-analyzer/internal/model"
+)
+
+// outcomeModel son las probabilidades del próximo sorteo por número, por terminación y por signo.
+type outcomeModel struct {
+	numbers   []float64
+	lastThree [1000]float64
+	lastTwo   [100]float64
+	lastOne   [10]float64
+	signs     []float64 // en el orden de model.Signs
+}
+
+func newOutcomeModel(numbers, signs []float64) *outcomeModel {
+	m := &outcomeModel{numbers: numbers, signs: signs}
+	for number, probability := range numbers {
+		m.lastThree[number%1000] += probability
+		m.lastTwo[number%100] += probability
+		m.lastOne[number%10] += probability
+	}
+	return m
+}
+
+// uniformOutcomes es el sorteo sin preferencias: todos los números y signos igual de probables.
+func uniformOutcomes() *outcomeModel {
+	numbers := make([]float64, 10000)
+	for i := range numbers {
+		numbers[i] = 1.0 / 10000
+	}
+	signs := make([]float64, len(model.Signs))
+	for i := range signs {
+		signs[i] = 1.0 / float64(len(signs))
+	}
+	return newOutcomeModel(numbers, signs)
+}
+
+// value devuelve la probabilidad de cobrar algún premio y el retorno esperado por unidad apostada.
+func (m *outcomeModel) value(ticket model.Ticket, table model.PrizeTable) (float64, float64, error) {
+	n := ticket.Number
+	p4, p3, p2, p1 := m.numbers[n], m.lastThree[n%1000], m.lastTwo[n%100], m.lastOne[n%10]
+
+	if ticket.Bet == BetCombined {
+		// Cada nivel paga sólo si no se acertó uno mayor
+		tiers := []struct {
+			bet         string
+			probability float64
+		}{{BetFour, p4}, {BetThree, p3 - p4}, {BetTwo, p2 - p3}, {BetOne, p1 - p2}}
+
+		win, expected := 0.0, 0.0
+		for _, tier := range tiers {
+			if prize := table.Combined[tier.bet]; prize > 0 {
+				win += tier.probability
+				expected += prize * tier.probability
+			}
+		}
+		if win == 0 {
+			return 0, 0, fmt.Errorf("lottery %d has no combined prizes", table.Lottery)
+		}
+		return win, expected, nil
+	}
+
+	prize, ok := table.Prizes[ticket.Bet]
+	if !ok {
+		return 0, 0, fmt.Errorf("lottery %d has no prize for bet %s", table.Lottery, ticket.Bet)
+	}
+
+	var probability float64
+	switch ticket.Bet {
+	case BetFour:
+		probability = p4
+	case BetThree:
+		probability = p3
+	case BetTwo:
+		probability = p2
+	case BetOne:
+		probability = p1
+	case BetFourSign:
+		idx := model.SignIndex(ticket.Sign)
+		if idx < 0 {
+			return 0, 0, fmt.Errorf("invalid sign %q for bet %s", ticket.Sign, ticket.Bet)
+		}
+		probability = p4 * m.signs[idx]
+	default:
+		return 0, 0, fmt.Errorf("unknown bet type: %s", ticket.Bet)
+	}
+
+	return probability, prize * probability, nil
+}
+
+// ticketValues valora cada apuesta con el modelo y con el sorteo uniforme y suma el total.
+func ticketValues(tickets []model.Ticket, table model.PrizeTable, modeled *outcomeModel) (*model.ExpectedValueReport, error) {
+	uniform := uniformOutcomes()
+	report := &model.ExpectedValueReport{Lottery: table.Lottery, PrizeTable: table}
+
+	for _, ticket := range tickets {
+		if ticket.Number < 0 || ticket.Number > 9999 {
+			return nil, fmt.Errorf("invalid number: %d", ticket.Number)
+		}
+		if ticket.Stake <= 0 {
+			ticket.Stake = 1
+		}
+
+		win, expected, err := modeled.value(ticket, table)
+		if err != nil {
+			return nil, err
+		}
+		uniformWin, uniformExpected, err := uniform.value(ticket, table)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Tickets = append(report.Tickets, model.TicketValue{
+			Ticket:                ticket,
+			WinProbability:        win,
+			UniformWinProbability: uniformWin,
+			ExpectedReturn:        expected,
+			UniformReturn:         uniformExpected,
+			ExpectedPayout:        expected * ticket.Stake,
+		})
+		report.Stake += ticket.Stake
+		report.ExpectedPayout += expected * ticket.Stake
+		report.UniformPayout += uniformExpected * ticket.Stake
+	}
+
+	if report.Stake > 0 {
+		report.ExpectedReturn = report.ExpectedPayout / report.Stake
+		report.UniformReturn = report.UniformPayout / report.Stake
+	}
+	return report, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestUniformTicketValues(t *testing.T) {
+	table := defaultPrizeTable
+	uniform := uniformOutcomes()
+
+	combined := 0.0001*2000 + (0.001-0.0001)*200 + (0.01-0.001)*20 + (0.1-0.01)*2
+	tests := []struct {
+		ticket   model.Ticket
+		win      float64
+		expected float64
+	}{
+		{model.Ticket{Number: 1234, Bet: BetFour}, 0.0001, 0.45},
+		{model.Ticket{Number: 1234, Bet: BetThree}, 0.001, 0.4},
+		{model.Ticket{Number: 1234, Bet: BetTwo}, 0.01, 0.5},
+		{model.Ticket{Number: 1234, Bet: BetOne}, 0.1, 0.5},
+		{model.Ticket{Number: 1234, Bet: BetFourSign, Sign: "A"}, 0.0001 / 12, 42000 * 0.0001 / 12},
+		{model.Ticket{Number: 1234, Bet: BetCombined}, 0.1, combined},
+	}
+
+	for _, tt := range tests {
+		win, expected, err := uniform.value(tt.ticket, table)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.ticket.Bet, err)
+		}
+		if math.Abs(win-tt.win) > 1e-12 || math.Abs(expected-tt.expected) > 1e-9 {
+			t.Errorf("%s: win %v expected %v, want %v %v", tt.ticket.Bet, win, expected, tt.win, tt.expected)
+		}
+	}
+}
+
+func TestTicketValueErrors(t *testing.T) {
+	uniform := uniformOutcomes()
+	tests := []struct {
+		name   string
+		ticket model.Ticket
+		table  model.PrizeTable
+	}{
+		{"sign bet without sign", model.Ticket{Number: 1, Bet: BetFourSign}, defaultPrizeTable},
+		{"unknown bet", model.Ticket{Number: 1, Bet: "five"}, defaultPrizeTable},
+		{"bet without prize", model.Ticket{Number: 1, Bet: BetTwo}, model.PrizeTable{Prizes: map[string]float64{BetFour: 1}}},
+		{"no combined prizes", model.Ticket{Number: 1, Bet: BetCombined}, model.PrizeTable{}},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := uniform.value(tt.ticket, tt.table); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestTicketValuesTotals(t *testing.T) {
+	tickets := []model.Ticket{
+		{Number: 1234, Bet: BetFour, Stake: 2},
+		{Number: 5678, Bet: BetTwo},
+	}
+	report, err := ticketValues(tickets, defaultPrizeTable, uniformOutcomes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Stake != 3 {
+		t.Errorf("stake = %v, want 3 (missing stake counts as 1)", report.Stake)
+	}
+	if want := 2*0.45 + 0.5; math.Abs(report.UniformPayout-want) > 1e-9 || math.Abs(report.ExpectedPayout-want) > 1e-9 {
+		t.Errorf("payouts = %v / %v, want %v", report.ExpectedPayout, report.UniformPayout, want)
+	}
+	if math.Abs(report.UniformReturn-report.UniformPayout/3) > 1e-12 {
+		t.Errorf("uniform return = %v, want %v", report.UniformReturn, report.UniformPayout/3)
+	}
+}
+
+func TestParseTickets(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []model.Ticket
+		wantErr bool
+	}{
+		{"number and bet", []string{"0427:four"}, []model.Ticket{{Number: 427, Bet: BetFour}}, false},
+		{"sign and stake", []string{"1234:four_sign:a:2.5"}, []model.Ticket{{Number: 1234, Bet: BetFourSign, Sign: "A", Stake: 2.5}}, false},
+		{"stake before sign", []string{" 12:two:3 ", ""}, []model.Ticket{{Number: 12, Bet: BetTwo, Stake: 3}}, false},
+		{"missing bet", []string{"1234"}, nil, true},
+		{"too many fields", []string{"1:two:A:1:2"}, nil, true},
+		{"five digits", []string{"12345:four"}, nil, true},
+		{"negative", []string{"-1:four"}, nil, true},
+		{"empty", []string{" "}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTickets(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTickets = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/repository"
+)
+
+// fakeResultRepo guarda en memoria los resultados de todas las loterías. Embebe la interfaz para
+// compilar: los métodos que los tests no usan entran en pánico si se llaman.
+type fakeResultRepo struct {
+	repository.ResultRepository
+
+	lottery int
+	data    *fakeResultData
+}
+
+type fakeResultData struct {
+	mu          sync.Mutex
+	results     []*model.Result
+	states      map[int]*model.FrequencyState
+	analyses    []*model.AnalysisRecord
+	frequencies map[int][]byte
+	nextID      int
+}
+
+func newFakeResultRepo(lottery int, results ...*model.Result) *fakeResultRepo {
+	r := &fakeResultRepo{lottery: lottery, data: &fakeResultData{
+		states:      make(map[int]*model.FrequencyState),
+		frequencies: make(map[int][]byte),
+	}}
+	for _, result := range results {
+		if result.Lottery == 0 {
+			result.Lottery = lottery
+		}
+		r.add(result)
+	}
+	return r
+}
+
+func (r *fakeResultRepo) add(result *model.Result) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	r.data.nextID++
+	if result.ID == 0 {
+		result.ID = r.data.nextID
+	}
+	r.data.results = append(r.data.results, result)
+}
+
+func (r *fakeResultRepo) Lottery() int { return r.lottery }
+
+func (r *fakeResultRepo) WithLottery(lottery int) repository.ResultRepository {
+	return &fakeResultRepo{lottery: lottery, data: r.data}
+}
+
+func (r *fakeResultRepo) Create(ctx context.Context, result *model.Result) error {
+	result.Lottery = r.lottery
+	r.add(result)
+	return nil
+}
+
+// filter devuelve copias de los resultados de la lotería que cumplen keep, ordenados por fecha e id.
+func (r *fakeResultRepo) filter(keep func(result *model.Result) bool) []*model.Result {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	var results []*model.Result
+	for _, result := range r.data.results {
+		if result.Lottery == r.lottery && keep(result) {
+			copied := *result
+			results = append(results, &copied)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if !results[i].Date.Equal(results[j].Date) {
+			return results[i].Date.Before(results[j].Date)
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+func (r *fakeResultRepo) Date(ctx context.Context, date time.Time) ([]*model.Result, error) {
+	return r.filter(func(result *model.Result) bool { return result.Date.Equal(date) }), nil
+}
+
+func (r *fakeResultRepo) BetweenDates(ctx context.Context, startDate, endDate time.Time) ([]*model.Result, error) {
+	return r.filter(func(result *model.Result) bool {
+		return !result.Date.Before(startDate) && !result.Date.After(endDate)
+	}), nil
+}
+
+func (r *fakeResultRepo) LastResult(ctx context.Context) (*model.Result, error) {
+	results := r.filter(func(*model.Result) bool { return true })
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return results[len(results)-1], nil
+}
+
+func (r *fakeResultRepo) Fingerprint(ctx context.Context, until time.Time) (*model.DataFingerprint, error) {
+	var fingerprint model.DataFingerprint
+	for _, result := range r.filter(func(result *model.Result) bool { return !result.Date.After(until) }) {
+		fingerprint.Count++
+		if result.ID > fingerprint.LastID {
+			fingerprint.LastID = result.ID
+		}
+		fingerprint.LastDate = result.Date.Format("02/01/2006")
+	}
+	return &fingerprint, nil
+}
+
+func (r *fakeResultRepo) FrequencyState(ctx context.Context) (*model.FrequencyState, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	state, ok := r.data.states[r.lottery]
+	if !ok {
+		return nil, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (r *fakeResultRepo) SaveFrequencyState(ctx context.Context, state *model.FrequencyState) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	copied := *state
+	r.data.states[r.lottery] = &copied
+	return nil
+}
+
+func (r *fakeResultRepo) SaveAnalysis(ctx context.Context, key model.AnalysisKey, analysis *[]byte, frequencies []byte) (int, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	record := &model.AnalysisRecord{
+		ID:        len(r.data.analyses) + 1,
+		Lottery:   r.lottery,
+		Key:       key,
+		CreatedAt: time.Now(),
+		Data:      append([]byte(nil), *analysis...),
+	}
+	r.data.analyses = append(r.data.analyses, record)
+	r.data.frequencies[record.ID] = frequencies
+	return record.ID, nil
+}
+
+func (r *fakeResultRepo) LastAnalysis(ctx context.Context, key model.AnalysisKey) (*model.AnalysisRecord, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	for i := len(r.data.analyses) - 1; i >= 0; i-- {
+		if record := r.data.analyses[i]; record.Lottery == r.lottery && record.Key == key {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeResultRepo) AnalysisByID(ctx context.Context, id int) (*model.AnalysisRecord, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	if id < 1 || id > len(r.data.analyses) || r.data.analyses[id-1].Lottery != r.lottery {
+		return nil, nil
+	}
+	copied := *r.data.analyses[id-1]
+	return &copied, nil
+}
+
+func (r *fakeResultRepo) AnalysisFrequencies(ctx context.Context, id int) (*[]byte, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+	if id < 1 || id > len(r.data.analyses) || r.data.analyses[id-1].Lottery != r.lottery {
+		return nil, nil
+	}
+	data := r.data.frequencies[id]
+	return &data, nil
+}
+
+func (r *fakeResultRepo) Lock(ctx context.Context, name string, timeout time.Duration) (func(), error) {
+	return func() {}, nil
+}
+
+// fakeTicketRepo guarda las apuestas en memoria; settleable usa los resultados de results.
+type fakeTicketRepo struct {
+	repository.TicketRepository
+
+	mu         sync.Mutex
+	tickets    []*model.PlayedTicket
+	results    *fakeResultRepo
+	failSettle bool
+}
+
+func (r *fakeTicketRepo) Create(ctx context.Context, ticket *model.PlayedTicket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ticket.ID = len(r.tickets) + 1
+	copied := *ticket
+	r.tickets = append(r.tickets, &copied)
+	return nil
+}
+
+func (r *fakeTicketRepo) Settleable(ctx context.Context, lottery int) ([]*model.PlayedTicket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pending []*model.PlayedTicket
+	for _, ticket := range r.tickets {
+		if ticket.Lottery != lottery || ticket.Status != TicketPending {
+			continue
+		}
+		results, _ := r.results.WithLottery(lottery).Date(ctx, ticket.Date)
+		if len(results) > 0 {
+			copied := *ticket
+			pending = append(pending, &copied)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeTicketRepo) Settle(ctx context.Context, ticket *model.PlayedTicket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failSettle {
+		return fmt.Errorf("settle failed")
+	}
+	copied := *ticket
+	r.tickets[ticket.ID-1] = &copied
+	return nil
+}
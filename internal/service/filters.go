@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"lottery-analyzer/internal/model"
+)
+
+// numberFilter decide qué números pueden entrar en el ranking de mejores números.
+type numberFilter struct {
+	include []string
+	exclude []string
+	shape   *shapeProfile
+
+	// preferred no excluye: ordena primero los números que cumplen el perfil de forma
+	preferred func(number int) bool
+}
+
+func newNumberFilter(params model.AnalysisParams) (*numberFilter, error) {
+	include, err := parsePatterns(params.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := parsePatterns(params.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	shape, err := parseShapeProfile(params.Shape)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &numberFilter{include: include, exclude: exclude}
+	switch params.ShapeMode {
+	case ShapeModePrefer:
+		if shape != nil {
+			filter.preferred = shape.matches
+		}
+	case ShapeModeFilter, "":
+		filter.shape = shape
+	default:
+		return nil, fmt.Errorf("unknown shape mode: %s", params.ShapeMode)
+	}
+
+	return filter, nil
+}
+
+// accepts exige el perfil de forma (en modo filtro), al menos una de las clases incluidas y ninguna de las excluidas.
+func (f *numberFilter) accepts(number int) bool {
+	if f.shape != nil && !f.shape.matches(number) {
+		return false
+	}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return true
+	}
+
+	classes := ClassifyNumber(number)
+	has := func(list []string) bool {
+		for _, class := range classes {
+			for _, wanted := range list {
+				if class == wanted {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if len(f.include) > 0 && !has(f.include) {
+		return false
+	}
+	return !has(f.exclude)
+}
+
+// canonicalPatterns ordena la lista de clases para que el orden no cambie los parámetros; si no es
+// válida se deja tal cual y el error sale al construir el filtro.
+func canonicalPatterns(list string) string {
+	classes, err := parsePatterns(list)
+	if err != nil {
+		return list
+	}
+	return strings.Join(classes, ",")
+}
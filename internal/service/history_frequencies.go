@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// draw es un resultado con su fecha truncada al día, igual que los límites de las ventanas.
+type draw struct {
+	date   time.Time
+	result *model.Result
+}
+
+// newDraws convierte los resultados a draws ordenados por fecha ascendente.
+func newDraws(results []*model.Result) ([]draw, error) {
+	draws := make([]draw, 0, len(results))
+	for _, r := range results {
+		if r.Date.IsZero() {
+			return nil, fmt.Errorf("result %d has no date", r.ID)
+		}
+		draws = append(draws, draw{date: day(r.Date), result: r})
+	}
+
+	sort.SliceStable(draws, func(i, j int) bool {
+		return draws[i].date.Before(draws[j].date)
+	})
+
+	return draws, nil
+}
+
+// windowCounts son los conteos de una ventana, los mismos que devuelven las consultas GROUP BY.
+type windowCounts struct {
+	digit      [4][10]int
+	twoDigit   [6][100]int
+	threeDigit [4][1000]int
+	fourDigit  [10000]int
+	sign       [13]int // la última casilla acumula los signos desconocidos
+	signDigit  [4][130]int
+}
+
+func (w *windowCounts) add(r *model.Result) {
+	w.apply(r, 1)
+}
+
+// apply suma delta a todas las casillas del resultado; con -1 lo quita de la ventana.
+func (w *windowCounts) apply(r *model.Result, delta int) {
+	d := [4]int{r.First, r.Second, r.Third, r.Fourth}
+
+	for i := 0; i < 4; i++ {
+		w.digit[i][d[i]] += delta
+	}
+
+	w.twoDigit[0][d[0]*10+d[1]] += delta
+	w.twoDigit[1][d[0]*10+d[2]] += delta
+	w.twoDigit[2][d[0]*10+d[3]] += delta
+	w.twoDigit[3][d[1]*10+d[2]] += delta
+	w.twoDigit[4][d[1]*10+d[3]] += delta
+	w.twoDigit[5][d[2]*10+d[3]] += delta
+
+	w.threeDigit[0][d[0]*100+d[1]*10+d[2]] += delta
+	w.threeDigit[1][d[0]*100+d[1]*10+d[3]] += delta
+	w.threeDigit[2][d[0]*100+d[2]*10+d[3]] += delta
+	w.threeDigit[3][d[1]*100+d[2]*10+d[3]] += delta
+
+	w.fourDigit[d[0]*1000+d[1]*100+d[2]*10+d[3]] += delta
+
+	signIdx := model.SignIndex(r.Sign)
+	if signIdx < 0 {
+		signIdx = len(model.Signs)
+	}
+	w.sign[signIdx] += delta
+	for i := 0; i < 4; i++ {
+		w.signDigit[i][signIdx*10+d[i]] += delta
+	}
+}
+
+// cells devuelve todas las casillas de la ventana, siempre en el mismo orden, para guardarlas.
+func (w *windowCounts) cells() [][]int {
+	cells := make([][]int, 0, 20)
+	for i := range w.digit {
+		cells = append(cells, w.digit[i][:])
+	}
+	for i := range w.twoDigit {
+		cells = append(cells, w.twoDigit[i][:])
+	}
+	for i := range w.threeDigit {
+		cells = append(cells, w.threeDigit[i][:])
+	}
+	cells = append(cells, w.fourDigit[:], w.sign[:])
+	for i := range w.signDigit {
+		cells = append(cells, w.signDigit[i][:])
+	}
+	return cells
+}
+
+// accumulate suma la ventana a las frecuencias usando las mismas funciones sumProb* que el cálculo
+// por SQL, de modo que ambos caminos producen exactamente los mismos valores.
+func (w *windowCounts) accumulate(f *model.FrequencyData) {
+	f.DigitFreq.Position1 = sumProbDigit(digitCounts(w.digit[0][:]), f.DigitFreq.Position1, digitFactors[0])
+	f.DigitFreq.Position2 = sumProbDigit(digitCounts(w.digit[1][:]), f.DigitFreq.Position2, digitFactors[1])
+	f.DigitFreq.Position3 = sumProbDigit(digitCounts(w.digit[2][:]), f.DigitFreq.Position3, digitFactors[2])
+	f.DigitFreq.Position4 = sumProbDigit(digitCounts(w.digit[3][:]), f.DigitFreq.Position4, digitFactors[3])
+
+	f.TwoDigitFreq.FirstSecond = sumProbTwoDigit(twoDigitCounts(w.twoDigit[0][:]), f.TwoDigitFreq.FirstSecond, twoDigitFactors[0])
+	f.TwoDigitFreq.FirstThird = sumProbTwoDigit(twoDigitCounts(w.twoDigit[1][:]), f.TwoDigitFreq.FirstThird, twoDigitFactors[1])
+	f.TwoDigitFreq.FirstFourth = sumProbTwoDigit(twoDigitCounts(w.twoDigit[2][:]), f.TwoDigitFreq.FirstFourth, twoDigitFactors[2])
+	f.TwoDigitFreq.SecondThird = sumProbTwoDigit(twoDigitCounts(w.twoDigit[3][:]), f.TwoDigitFreq.SecondThird, twoDigitFactors[3])
+	f.TwoDigitFreq.SecondFourth = sumProbTwoDigit(twoDigitCounts(w.twoDigit[4][:]), f.TwoDigitFreq.SecondFourth, twoDigitFactors[4])
+	f.TwoDigitFreq.ThirdFourth = sumProbTwoDigit(twoDigitCounts(w.twoDigit[5][:]), f.TwoDigitFreq.ThirdFourth, twoDigitFactors[5])
+
+	f.ThreeDigitFreq.FirstSecondThird = sumProbThreeDigit(threeDigitCounts(w.threeDigit[0][:]), f.ThreeDigitFreq.FirstSecondThird, threeDigitFactors[0])
+	f.ThreeDigitFreq.FirstSecondFourth = sumProbThreeDigit(threeDigitCounts(w.threeDigit[1][:]), f.ThreeDigitFreq.FirstSecondFourth, threeDigitFactors[1])
+	f.ThreeDigitFreq.FirstThirdFourth = sumProbThreeDigit(threeDigitCounts(w.threeDigit[2][:]), f.ThreeDigitFreq.FirstThirdFourth, threeDigitFactors[2])
+	f.ThreeDigitFreq.SecondThirdFourth = sumProbThreeDigit(threeDigitCounts(w.threeDigit[3][:]), f.ThreeDigitFreq.SecondThirdFourth, threeDigitFactors[3])
+
+	totalResults := 0
+	for _, c := range w.fourDigit {
+		totalResults += c
+	}
+	for number, c := range w.fourDigit {
+		if c > 0 {
+			f.FourDigitFreq.Complete[number] += (float64(c) / float64(totalResults)) * 1000
+		}
+	}
+
+	f.SignFreq.Signs = sumProbSign(signCounts(w.sign[:]), f.SignFreq.Signs, 1.0)
+	sumProbSignDigit(signDigitCounts(w.signDigit[0][:]), f.SignFreq.Position1, 1.0)
+	sumProbSignDigit(signDigitCounts(w.signDigit[1][:]), f.SignFreq.Position2, 1.0)
+	sumProbSignDigit(signDigitCounts(w.signDigit[2][:]), f.SignFreq.Position3, 1.0)
+	sumProbSignDigit(signDigitCounts(w.signDigit[3][:]), f.SignFreq.Position4, 1.0)
+}
+
+// FrequenciesFromResults calcula en memoria las mismas frecuencias que CalculateFrequencies,
+// con las ventanas ancladas en from y usando sólo los resultados recibidos.
+func FrequenciesFromResults(results []*model.Result, from time.Time) (*model.FrequencyData, int, error) {
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	frequencyData, processed := frequenciesFromDraws(draws, from)
+	return frequencyData, processed, nil
+}
+
+// frequenciesFromDraws recorre las ventanas sobre draws ya ordenados por fecha.
+func frequenciesFromDraws(draws []draw, from time.Time) (*model.FrequencyData, int) {
+	frequencyData := newFrequencyData()
+	windows := fibonacciWindows(from)
+
+	// Las ventanas se van ampliando hacia atrás, así que cada una parte de los conteos de la anterior
+	counts := &windowCounts{}
+	next := len(draws)
+	for _, cutoff := range windows {
+		for next > 0 && draws[next-1].date.After(cutoff) {
+			next--
+			counts.add(draws[next].result)
+		}
+		counts.accumulate(frequencyData)
+	}
+
+	return frequencyData, len(windows)
+}
+
+func digitCounts(counts []int) []*model.DigitCount {
+	var result []*model.DigitCount
+	for digit, c := range counts {
+		if c > 0 {
+			result = append(result, &model.DigitCount{Digit: digit, Count: c})
+		}
+	}
+	return result
+}
+
+func twoDigitCounts(counts []int) []*model.TwoDigitCount {
+	var result []*model.TwoDigitCount
+	for value, c := range counts {
+		if c > 0 {
+			result = append(result, &model.TwoDigitCount{Count: c, FirstDigit: value / 10, SecondDigit: value % 10})
+		}
+	}
+	return result
+}
+
+func threeDigitCounts(counts []int) []*model.ThreeDigitCount {
+	var result []*model.ThreeDigitCount
+	for value, c := range counts {
+		if c > 0 {
+			result = append(result, &model.ThreeDigitCount{Count: c, FirstDigit: value / 100, SecondDigit: value / 10 % 10, ThirdDigit: value % 10})
+		}
+	}
+	return result
+}
+
+func signCounts(counts []int) []*model.SignCount {
+	var result []*model.SignCount
+	for idx, c := range counts {
+		if c > 0 {
+			result = append(result, &model.SignCount{Sign: signAt(idx), Count: c})
+		}
+	}
+	return result
+}
+
+func signDigitCounts(counts []int) []*model.SignDigitCount {
+	var result []*model.SignDigitCount
+	for value, c := range counts {
+		if c > 0 {
+			result = append(result, &model.SignDigitCount{Sign: signAt(value / 10), Digit: value % 10, Count: c})
+		}
+	}
+	return result
+}
+
+// signAt devuelve la letra del signo o "Z" para la casilla de desconocidos.
+func signAt(idx int) string {
+	if idx < len(model.Signs) {
+		return model.Signs[idx]
+	}
+	return "Z"
+}
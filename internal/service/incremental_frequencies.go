@@ -0,0 +1,365 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/repository"
+)
+
+// Cómo se obtuvieron las frecuencias incrementales
+const (
+	FrequenciesLoaded   = "loaded"   // el estado guardado ya era el pedido
+	FrequenciesAdvanced = "advanced" // se actualizó el estado guardado con los sorteos nuevos
+	FrequenciesRebuilt  = "rebuilt"  // se recalculó desde el histórico
+)
+
+// frequencyWindows son los conteos de cada ventana fibonacci anclada en asOf, con los sorteos hasta
+// until inclusive. A diferencia de frequenciesFromDraws guarda los conteos de todas las ventanas para
+// poder moverlas sin recorrer el histórico.
+type frequencyWindows struct {
+	asOf        time.Time
+	until       time.Time
+	fingerprint model.DataFingerprint
+	counts      []windowCounts
+}
+
+// buildWindows cuenta las ventanas con draws ordenados por fecha y sin sorteos posteriores a until.
+func buildWindows(draws []draw, asOf, until time.Time) *frequencyWindows {
+	bounds := fibonacciWindows(asOf)
+	w := &frequencyWindows{asOf: asOf, until: until, counts: make([]windowCounts, len(bounds))}
+
+	// Las ventanas están anidadas: cada una parte de los conteos de la anterior
+	current := windowCounts{}
+	next := len(draws)
+	for i, bound := range bounds {
+		for next > 0 && draws[next-1].date.After(bound) {
+			next--
+			current.add(draws[next].result)
+		}
+		w.counts[i] = current
+	}
+	return w
+}
+
+// frequencyData suma las ventanas en el mismo orden que CalculateFrequencies.
+func (w *frequencyWindows) frequencyData(ctx context.Context) *model.FrequencyData {
+	frequencyData := newFrequencyData()
+	for i := range w.counts {
+		w.counts[i].accumulate(frequencyData)
+		reportWindows(ctx, i+1, len(w.counts))
+	}
+	return frequencyData
+}
+
+// advance mueve las ventanas a asOf y until, que no pueden ser anteriores a las actuales: cada
+// ventana suma los sorteos nuevos que caen dentro de ella y resta los que quedaron antes de su
+// nuevo límite inferior.
+func (w *frequencyWindows) advance(ctx context.Context, resultRepo repository.ResultRepository, asOf, until time.Time) error {
+	oldBounds := fibonacciWindows(w.asOf)
+	newBounds := fibonacciWindows(asOf)
+
+	if until.After(w.until) {
+		results, err := resultRepo.BetweenDates(ctx, w.until.AddDate(0, 0, 1), until)
+		if err != nil {
+			return fmt.Errorf("failed to get new results: %w", err)
+		}
+		draws, err := newDraws(results)
+		if err != nil {
+			return err
+		}
+
+		for i := range w.counts {
+			for _, d := range draws {
+				if d.date.After(newBounds[i]) {
+					w.counts[i].add(d.result)
+				}
+			}
+		}
+	}
+
+	// Los sorteos que salen de cada ventana son los que ya estaban contados (hasta el until anterior)
+	for i := range w.counts {
+		end := newBounds[i]
+		if end.After(w.until) {
+			end = w.until
+		}
+		if !end.After(oldBounds[i]) {
+			continue
+		}
+
+		results, err := resultRepo.BetweenDates(ctx, oldBounds[i].AddDate(0, 0, 1), end)
+		if err != nil {
+			return fmt.Errorf("failed to get results leaving window %d: %w", i, err)
+		}
+		for _, r := range results {
+			w.counts[i].apply(r, -1)
+		}
+	}
+
+	w.asOf, w.until = asOf, until
+	return nil
+}
+
+// encode guarda todas las casillas de todas las ventanas como uvarint comprimido con gzip; casi
+// todas valen cero, así que el estado ocupa poco.
+func (w *frequencyWindows) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	bw := bufio.NewWriter(zw)
+
+	var scratch [binary.MaxVarintLen64]byte
+	for i := range w.counts {
+		for _, cells := range w.counts[i].cells() {
+			for _, c := range cells {
+				if c < 0 {
+					return nil, fmt.Errorf("negative count in window %d", i)
+				}
+				n := binary.PutUvarint(scratch[:], uint64(c))
+				if _, err := bw.Write(scratch[:n]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeWindows reconstruye las ventanas de un estado guardado.
+func decodeWindows(state *model.FrequencyState) (*frequencyWindows, error) {
+	if state.Windows != len(fibonacciWindows(state.AsOf)) {
+		return nil, fmt.Errorf("state has %d windows", state.Windows)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(state.Data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	w := &frequencyWindows{
+		asOf:        state.AsOf,
+		until:       state.Until,
+		fingerprint: state.Fingerprint,
+		counts:      make([]windowCounts, state.Windows),
+	}
+	for i := range w.counts {
+		for _, cells := range w.counts[i].cells() {
+			for j := range cells {
+				c, err := binary.ReadUvarint(br)
+				if err != nil {
+					return nil, fmt.Errorf("truncated state in window %d: %w", i, err)
+				}
+				cells[j] = int(c)
+			}
+		}
+	}
+
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the last window")
+	}
+	return w, nil
+}
+
+// IncrementalFrequencies devuelve las mismas frecuencias que CalculateFrequencies partiendo de los
+// conteos guardados de la lotería: si los sorteos hasta el corte guardado no cambiaron y el pedido
+// no es anterior, sólo se aplican los sorteos nuevos y los que salen de cada ventana.
+func IncrementalFrequencies(ctx context.Context, resultRepo repository.ResultRepository, asOf, until time.Time) (*model.FrequencyData, int, error) {
+	w, _, err := incrementalWindows(ctx, resultRepo, asOf, until)
+	if err != nil {
+		return nil, 0, err
+	}
+	return w.frequencyData(ctx), len(w.counts), nil
+}
+
+func incrementalWindows(ctx context.Context, resultRepo repository.ResultRepository, asOf, until time.Time) (*frequencyWindows, string, error) {
+	asOf, until = day(asOf), day(until)
+
+	state, err := resultRepo.FrequencyState(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get frequency state: %w", err)
+	}
+
+	// La huella se toma antes de leer los sorteos: si entra uno mientras tanto, el estado guardado
+	// queda con la huella vieja y la próxima vez se recalcula
+	fingerprint, err := resultRepo.Fingerprint(ctx, until)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get data fingerprint: %w", err)
+	}
+
+	forward := state != nil && !asOf.Before(state.AsOf) && !until.Before(state.Until)
+	w, how, err := loadWindows(ctx, resultRepo, state, forward)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case w == nil:
+		results, err := resultRepo.BetweenDates(ctx, time.Time{}, until)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get history: %w", err)
+		}
+		draws, err := newDraws(results)
+		if err != nil {
+			return nil, "", err
+		}
+		w, how = buildWindows(draws, asOf, until), FrequenciesRebuilt
+	case !asOf.Equal(w.asOf) || !until.Equal(w.until):
+		if err := w.advance(ctx, resultRepo, asOf, until); err != nil {
+			return nil, "", fmt.Errorf("failed to advance frequency windows: %w", err)
+		}
+		how = FrequenciesAdvanced
+	}
+	w.fingerprint = *fingerprint
+
+	// Los análisis de fechas pasadas no pisan el estado, que sigue sirviendo para el día actual
+	if (state == nil || forward) && how != FrequenciesLoaded {
+		if err := saveWindows(ctx, resultRepo, w); err != nil {
+			return nil, "", err
+		}
+	}
+	return w, how, nil
+}
+
+// loadWindows devuelve el estado guardado si sirve para avanzar, o nil si hay que recalcular.
+func loadWindows(ctx context.Context, resultRepo repository.ResultRepository, state *model.FrequencyState, forward bool) (*frequencyWindows, string, error) {
+	if state == nil || !forward {
+		return nil, "", nil
+	}
+
+	// Un sorteo agregado o borrado antes del corte guardado cambia la huella e invalida los conteos; la
+	// huella no ve un sorteo corregido en el lugar, por eso Update del repositorio borra el estado
+	current, err := resultRepo.Fingerprint(ctx, state.Until)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get data fingerprint: %w", err)
+	}
+	if *current != state.Fingerprint {
+		return nil, "", nil
+	}
+
+	w, err := decodeWindows(state)
+	if err != nil {
+		log.Printf("Discarding frequency state: %v", err)
+		return nil, "", nil
+	}
+	return w, FrequenciesLoaded, nil
+}
+
+func saveWindows(ctx context.Context, resultRepo repository.ResultRepository, w *frequencyWindows) error {
+	data, err := w.encode()
+	if err != nil {
+		return fmt.Errorf("failed to encode frequency state: %w", err)
+	}
+
+	state := &model.FrequencyState{
+		AsOf:        w.asOf,
+		Until:       w.until,
+		Fingerprint: w.fingerprint,
+		Windows:     len(w.counts),
+		Data:        data,
+		UpdatedAt:   time.Now(),
+	}
+	if err := resultRepo.SaveFrequencyState(ctx, state); err != nil {
+		return fmt.Errorf("failed to save frequency state: %w", err)
+	}
+	return nil
+}
+
+// frequencyTable es una de las tablas de FrequencyData con el nombre de su grupo y su campo JSON.
+type frequencyTable struct {
+	group  string
+	name   string
+	values []float64
+}
+
+// frequencyTables lista todas las tablas de f en un orden fijo.
+func frequencyTables(f *model.FrequencyData) []frequencyTable {
+	return []frequencyTable{
+		{"digit", "position_1", f.DigitFreq.Position1},
+		{"digit", "position_2", f.DigitFreq.Position2},
+		{"digit", "position_3", f.DigitFreq.Position3},
+		{"digit", "position_4", f.DigitFreq.Position4},
+		{"two_digit", "first_second", f.TwoDigitFreq.FirstSecond},
+		{"two_digit", "first_third", f.TwoDigitFreq.FirstThird},
+		{"two_digit", "first_fourth", f.TwoDigitFreq.FirstFourth},
+		{"two_digit", "second_third", f.TwoDigitFreq.SecondThird},
+		{"two_digit", "second_fourth", f.TwoDigitFreq.SecondFourth},
+		{"two_digit", "third_fourth", f.TwoDigitFreq.ThirdFourth},
+		{"three_digit", "first_second_third", f.ThreeDigitFreq.FirstSecondThird},
+		{"three_digit", "first_second_fourth", f.ThreeDigitFreq.FirstSecondFourth},
+		{"three_digit", "first_third_fourth", f.ThreeDigitFreq.FirstThirdFourth},
+		{"three_digit", "second_third_fourth", f.ThreeDigitFreq.SecondThirdFourth},
+		{"four_digit", "complete", f.FourDigitFreq.Complete},
+		{"sign", "signs", f.SignFreq.Signs},
+		{"sign", "position_1", f.SignFreq.Position1},
+		{"sign", "position_2", f.SignFreq.Position2},
+		{"sign", "position_3", f.SignFreq.Position3},
+		{"sign", "position_4", f.SignFreq.Position4},
+	}
+}
+
+// VerifyFrequencies calcula las frecuencias de params con el estado incremental y con las consultas
+// SQL de siempre y compara todas las casillas: tienen que ser exactamente iguales.
+func (p *processorService) VerifyFrequencies(ctx context.Context, params model.AnalysisParams) (*model.FrequencyCheck, error) {
+	params = normalizeParams(params)
+	asOf, cutoff, err := analysisDates(params)
+	if err != nil {
+		return nil, err
+	}
+
+	w, how, err := incrementalWindows(ctx, p.resultRepo, asOf, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate incremental frequencies: %w", err)
+	}
+	incremental := w.frequencyData(ctx)
+
+	full, windows, err := CalculateFrequencies(ctx, p.resultRepo, asOf, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate frequencies: %w", err)
+	}
+
+	check := &model.FrequencyCheck{
+		AsOf:        params.AsOf,
+		Cutoff:      params.Cutoff,
+		Windows:     windows,
+		Incremental: how,
+		Consistent:  windows == len(w.counts),
+	}
+
+	expected := frequencyTables(full)
+	for i, table := range frequencyTables(incremental) {
+		tableCheck := model.FrequencyTableCheck{Table: table.group + "." + table.name, Cells: len(table.values)}
+		if len(table.values) != len(expected[i].values) {
+			tableCheck.Mismatches = len(table.values)
+		}
+		for j := 0; j < len(table.values) && j < len(expected[i].values); j++ {
+			if diff := math.Abs(table.values[j] - expected[i].values[j]); table.values[j] != expected[i].values[j] {
+				tableCheck.Mismatches++
+				tableCheck.MaxDiff = math.Max(tableCheck.MaxDiff, diff)
+			}
+		}
+		if tableCheck.Mismatches > 0 {
+			check.Consistent = false
+		}
+		check.Tables = append(check.Tables, tableCheck)
+	}
+
+	return check, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+func testDate(s string) time.Time {
+	date, err := time.Parse(AsOfLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// drawsUntil son los draws del histórico hasta until inclusive.
+func drawsUntil(t *testing.T, history []*model.Result, until time.Time) []draw {
+	var results []*model.Result
+	for _, result := range history {
+		if !result.Date.After(until) {
+			results = append(results, result)
+		}
+	}
+	draws, err := newDraws(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return draws
+}
+
+func sameWindows(t *testing.T, got, want *frequencyWindows) {
+	t.Helper()
+	if !got.asOf.Equal(want.asOf) || !got.until.Equal(want.until) {
+		t.Fatalf("windows at %v/%v, want %v/%v", got.asOf, got.until, want.asOf, want.until)
+	}
+	if len(got.counts) != len(want.counts) {
+		t.Fatalf("%d windows, want %d", len(got.counts), len(want.counts))
+	}
+	for i := range got.counts {
+		if got.counts[i] != want.counts[i] {
+			t.Fatalf("window %d differs from the full count", i)
+		}
+	}
+}
+
+func TestWindowCountsApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		result model.Result
+		sign   int
+	}{
+		{"known sign", model.Result{First: 1, Second: 2, Third: 3, Fourth: 4, Sign: model.Signs[5]}, 5},
+		{"unknown sign", model.Result{First: 9, Second: 0, Third: 9, Fourth: 0, Sign: "?"}, len(model.Signs)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w windowCounts
+			w.add(&tt.result)
+			w.add(&tt.result)
+
+			d := [4]int{tt.result.First, tt.result.Second, tt.result.Third, tt.result.Fourth}
+			number := d[0]*1000 + d[1]*100 + d[2]*10 + d[3]
+			if w.fourDigit[number] != 2 || w.sign[tt.sign] != 2 || w.signDigit[3][tt.sign*10+d[3]] != 2 {
+				t.Fatalf("counts after two adds: number %d, sign %d, sign digit %d",
+					w.fourDigit[number], w.sign[tt.sign], w.signDigit[3][tt.sign*10+d[3]])
+			}
+			if w.twoDigit[2][d[0]*10+d[3]] != 2 || w.threeDigit[3][d[1]*100+d[2]*10+d[3]] != 2 {
+				t.Fatal("pair or triple counts are not 2")
+			}
+
+			w.apply(&tt.result, -2)
+			if w != (windowCounts{}) {
+				t.Fatal("removing the result did not leave the window empty")
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeWindows(t *testing.T) {
+	history := testHistory(400, 5)
+	history[10].Sign = ""
+	asOf, until := testDate("2021-02-01"), testDate("2021-01-31")
+	w := buildWindows(drawsUntil(t, history, until), asOf, until)
+
+	data, err := w.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &model.FrequencyState{AsOf: asOf, Until: until, Windows: len(w.counts), Data: data}
+
+	decoded, err := decodeWindows(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameWindows(t, decoded, w)
+
+	tests := []struct {
+		name  string
+		state model.FrequencyState
+	}{
+		{"wrong window count", model.FrequencyState{AsOf: asOf, Until: until, Windows: len(w.counts) - 1, Data: data}},
+		{"not gzip", model.FrequencyState{AsOf: asOf, Until: until, Windows: len(w.counts), Data: []byte("counts")}},
+		{"truncated", model.FrequencyState{AsOf: asOf, Until: until, Windows: len(w.counts), Data: data[:len(data)/2]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeWindows(&tt.state); err == nil {
+				t.Error("decodeWindows accepted a broken state")
+			}
+		})
+	}
+}
+
+func TestAdvanceMatchesBuild(t *testing.T) {
+	history := testHistory(400, 6)
+	repo := newFakeResultRepo(1, history...)
+
+	tests := []struct {
+		name                string
+		fromAsOf, fromUntil string
+		toAsOf, toUntil     string
+	}{
+		{"next day", "2020-12-01", "2020-11-30", "2020-12-02", "2020-12-01"},
+		{"same day, later cutoff", "2020-12-01", "2020-11-20", "2020-12-01", "2020-11-30"},
+		{"same cutoff, later day", "2020-12-01", "2020-11-30", "2020-12-20", "2020-11-30"},
+		{"several weeks", "2020-06-01", "2020-05-31", "2020-09-15", "2020-09-14"},
+		{"past the last draw", "2020-12-01", "2020-11-30", "2021-03-01", "2021-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromAsOf, fromUntil := testDate(tt.fromAsOf), testDate(tt.fromUntil)
+			toAsOf, toUntil := testDate(tt.toAsOf), testDate(tt.toUntil)
+
+			w := buildWindows(drawsUntil(t, history, fromUntil), fromAsOf, fromUntil)
+			if err := w.advance(context.Background(), repo, toAsOf, toUntil); err != nil {
+				t.Fatal(err)
+			}
+			sameWindows(t, w, buildWindows(drawsUntil(t, history, toUntil), toAsOf, toUntil))
+		})
+	}
+}
+
+func TestIncrementalWindows(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeResultRepo(1, testHistory(300, 7)...)
+
+	steps := []struct {
+		name        string
+		asOf, until string
+		add         *model.Result
+		want        string
+	}{
+		{"first time", "2020-09-01", "2020-08-31", nil, FrequenciesRebuilt},
+		{"same request", "2020-09-01", "2020-08-31", nil, FrequenciesLoaded},
+		{"next day", "2020-09-02", "2020-09-01", nil, FrequenciesAdvanced},
+		{"past date", "2020-05-01", "2020-04-30", nil, FrequenciesRebuilt},
+		{"past date does not replace the state", "2020-09-02", "2020-09-01", nil, FrequenciesLoaded},
+		{"draw added before the saved cutoff", "2020-09-02", "2020-09-01",
+			&model.Result{Date: testDate("2020-08-15"), First: 1, Second: 2, Third: 3, Fourth: 4}, FrequenciesRebuilt},
+	}
+
+	for _, step := range steps {
+		if step.add != nil {
+			repo.Create(ctx, step.add)
+		}
+		asOf, until := testDate(step.asOf), testDate(step.until)
+
+		w, how, err := incrementalWindows(ctx, repo, asOf, until)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if how != step.want {
+			t.Errorf("%s: frequencies %s, want %s", step.name, how, step.want)
+		}
+
+		results, _ := repo.BetweenDates(ctx, time.Time{}, until)
+		sameWindows(t, w, buildWindows(drawsUntil(t, results, until), asOf, until))
+	}
+}
@@ -12,13 +12,43 @@ type ScrapperService interface {
 	ScrapingFromLastDate(ctx context.Context) error
 	ScrapingDateRange(ctx context.Context, startDate, endDate time.Time) error
 	LastScrapedDate(ctx context.Context) (*time.Time, error)
+	OnNewResult(listener func(result *model.Result))
 }
 
 // ProcessorService define las operaciones de análisis y procesamiento
 type ProcessorService interface {
-	ProcessAnalysis(ctx context.Context) (*model.Analysis, error)
-	BestNumbers(ctx context.Context, limit int) ([]int, []float64, error)
+	ProcessAnalysis(ctx context.Context, params model.AnalysisParams) (*model.Analysis, error)
+	BestNumbers(ctx context.Context, params model.AnalysisParams, limit int) (*model.BestNumbers, error)
+	Analyses(ctx context.Context, filter model.AnalysisFilter) (*model.AnalysisPage, error)
+	AnalysisByID(ctx context.Context, id int) (*model.Analysis, error)
+	AnalysisFrequencies(ctx context.Context, id int, group, name string) (*model.AnalysisFrequencies, error)
+	DiffAnalyses(ctx context.Context, fromID, toID int, mode string, top int) (*model.RankingDiff, error)
 	UnplayedNumbers(ctx context.Context) (int, error)
-	CalculateProbability(ctx context.Context, number int) (float64, error)
-	Statistics(ctx context.Context) (*model.Statistics, error)
+	CalculateProbability(ctx context.Context, params model.AnalysisParams, number int) (*model.NumberScore, error)
+	Statistics(ctx context.Context, startDate, endDate time.Time) (*model.Statistics, error)
+	PatternAnalysis(ctx context.Context, startDate, endDate time.Time) (*model.PatternReport, error)
+	ShapeAnalysis(ctx context.Context, startDate, endDate time.Time) (*model.ShapeReport, error)
+	Seasonality(ctx context.Context, startDate, endDate time.Time) (*model.SeasonalityReport, error)
+	Dependencies(ctx context.Context, startDate, endDate time.Time, params model.DependencyParams) (*model.DependencyReport, error)
+	Wheel(ctx context.Context, params model.AnalysisParams, wheel model.WheelParams) (*model.WheelResult, error)
+	ExpectedValue(ctx context.Context, params model.AnalysisParams, tickets []model.Ticket, temperature float64) (*model.ExpectedValueReport, error)
+	VerifyFrequencies(ctx context.Context, params model.AnalysisParams) (*model.FrequencyCheck, error)
+	Correlation(ctx context.Context, lotteryA, lotteryB int, startDate, endDate time.Time) (*model.CorrelationReport, error)
+}
+
+// LedgerService define el registro y la liquidación de las apuestas compradas
+type LedgerService interface {
+	Register(ctx context.Context, ticket *model.PlayedTicket) error
+	Settle(ctx context.Context, lottery int) (int, error)
+	Tickets(ctx context.Context, filter model.TicketFilter) ([]*model.PlayedTicket, error)
+	Summary(ctx context.Context, filter model.TicketFilter, by string) (*model.LedgerReport, error)
+}
+
+// JobService define la ejecución de análisis en segundo plano
+type JobService interface {
+	Submit(params model.AnalysisParams) (*model.Job, error)
+	Job(id string) (*model.Job, error)
+	Jobs() []*model.Job
+	Cancel(id string) (*model.Job, error)
+	Close()
 }
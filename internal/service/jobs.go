@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+// Estados de un trabajo de análisis.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+const (
+	// maxRunningJobs limita los análisis simultáneos; los demás esperan en cola
+	maxRunningJobs = 2
+	// jobRetention es cuánto se guarda un trabajo terminado en memoria
+	jobRetention = time.Hour
+)
+
+type job struct {
+	model.Job
+	progress *Progress
+	cancel   context.CancelFunc
+}
+
+type jobService struct {
+	processor ProcessorService
+	ctx       context.Context
+	stop      context.CancelFunc
+	slots     chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewJobService crea la cola de análisis en segundo plano. Los trabajos no dependen de la petición
+// que los creó, sólo se cancelan con Cancel o con Close.
+func NewJobService(processor ProcessorService) JobService {
+	ctx, stop := context.WithCancel(context.Background())
+	return &jobService{
+		processor: processor,
+		ctx:       ctx,
+		stop:      stop,
+		slots:     make(chan struct{}, maxRunningJobs),
+		jobs:      make(map[string]*job),
+	}
+}
+
+func (s *jobService) Submit(params model.AnalysisParams) (*model.Job, error) {
+	params = normalizeParams(params)
+	if err := validMode(params.Mode); err != nil {
+		return nil, err
+	}
+	if _, _, err := analysisDates(params); err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	j := &job{
+		Job:      model.Job{ID: id, State: JobQueued, Params: params, SubmittedAt: time.Now()},
+		progress: &Progress{},
+		cancel:   cancel,
+	}
+
+	s.mu.Lock()
+	s.purge()
+	s.jobs[id] = j
+	snapshot := s.snapshot(j)
+	s.mu.Unlock()
+
+	go s.run(WithProgress(ctx, j.progress), j)
+	return snapshot, nil
+}
+
+func (s *jobService) run(ctx context.Context, j *job) {
+	// Esperar un lugar libre salvo que cancelen el trabajo en cola
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finish(ctx, j, nil, ctx.Err())
+		return
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	j.State = JobRunning
+	j.StartedAt = &now
+	s.mu.Unlock()
+
+	analysis, err := s.processor.ProcessAnalysis(ctx, j.Params)
+	s.finish(ctx, j, analysis, err)
+}
+
+func (s *jobService) finish(ctx context.Context, j *job, analysis *model.Analysis, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer j.cancel()
+
+	now := time.Now()
+	j.FinishedAt = &now
+
+	switch {
+	case err == nil:
+		j.State = JobSucceeded
+		j.Analysis = analysis
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		j.State = JobCancelled
+	default:
+		j.State = JobFailed
+		j.Error = err.Error()
+	}
+}
+
+func (s *jobService) Job(id string) (*model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return s.snapshot(j), nil
+}
+
+// Jobs devuelve los trabajos en memoria, del más nuevo al más viejo, sin el análisis.
+func (s *jobService) Jobs() []*model.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*model.Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		snapshot := s.snapshot(j)
+		snapshot.Analysis = nil
+		jobs = append(jobs, snapshot)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].SubmittedAt.After(jobs[b].SubmittedAt)
+	})
+	return jobs
+}
+
+// Cancel pide que se detenga el trabajo; el estado pasa a cancelled cuando el análisis se entera.
+func (s *jobService) Cancel(id string) (*model.Job, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+
+	j.cancel()
+	return s.Job(id)
+}
+
+// Close cancela todos los trabajos pendientes.
+func (s *jobService) Close() {
+	s.stop()
+}
+
+// snapshot copia el trabajo con el progreso actual; hay que llamarla con el lock tomado.
+func (s *jobService) snapshot(j *job) *model.Job {
+	snapshot := j.Job
+	snapshot.Progress = j.progress.Snapshot()
+
+	if j.StartedAt != nil {
+		end := time.Now()
+		if j.FinishedAt != nil {
+			end = *j.FinishedAt
+		}
+		snapshot.Duration = end.Sub(*j.StartedAt).String()
+	}
+	return &snapshot
+}
+
+// purge borra los trabajos terminados hace más de jobRetention; hay que llamarla con el lock tomado.
+func (s *jobService) purge() {
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && time.Since(*j.FinishedAt) > jobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
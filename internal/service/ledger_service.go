@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/internal/repository"
+)
+
+// Estados de una apuesta registrada.
+const (
+	TicketPending = "pending"
+	TicketWon     = "won"
+	TicketLost    = "lost"
+)
+
+// Agrupaciones del resumen de apuestas.
+const (
+	LedgerByUser     = "user"
+	LedgerByStrategy = "strategy"
+)
+
+type ledgerService struct {
+	ticketRepo repository.TicketRepository
+	resultRepo repository.ResultRepository
+	prizes     PrizeTables
+}
+
+func NewLedgerService(ticketRepo repository.TicketRepository, resultRepo repository.ResultRepository, prizes PrizeTables) LedgerService {
+	return &ledgerService{
+		ticketRepo: ticketRepo,
+		resultRepo: resultRepo,
+		prizes:     prizes,
+	}
+}
+
+// Register guarda una apuesta pendiente; si el resultado de su sorteo ya está guardado se liquida
+// en el momento. Una vez guardada la apuesta no se devuelve error, así un reintento del cliente no la
+// duplica: si no se pudo liquidar queda pendiente para el próximo Settle.
+func (l *ledgerService) Register(ctx context.Context, ticket *model.PlayedTicket) error {
+	if err := l.validTicket(ticket); err != nil {
+		return err
+	}
+
+	ticket.Status = TicketPending
+	ticket.Payout = 0
+	ticket.CreatedAt = time.Now()
+	if err := l.ticketRepo.Create(ctx, ticket); err != nil {
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	results, err := l.resultRepo.WithLottery(ticket.Lottery).Date(ctx, ticket.Date)
+	if err != nil {
+		log.Printf("Ticket %d left pending, failed to get result: %v", ticket.ID, err)
+		return nil
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	if err := l.settle(ctx, ticket, results[0]); err != nil {
+		log.Printf("Ticket %d left pending: %v", ticket.ID, err)
+		ticket.Status, ticket.Payout, ticket.ResultID, ticket.SettledAt = TicketPending, 0, 0, nil
+	}
+	return nil
+}
+
+// Settle liquida todas las apuestas pendientes de la lotería cuyo sorteo ya está guardado, no sólo
+// las del último: así también se liquidan las que quedaron pendientes por un error anterior.
+// Devuelve cuántas liquidó.
+func (l *ledgerService) Settle(ctx context.Context, lottery int) (int, error) {
+	tickets, err := l.ticketRepo.Settleable(ctx, lottery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending tickets: %w", err)
+	}
+
+	resultRepo := l.resultRepo.WithLottery(lottery)
+	results := make(map[time.Time]*model.Result)
+	settled := 0
+	for _, ticket := range tickets {
+		result, ok := results[ticket.Date]
+		if !ok {
+			found, err := resultRepo.Date(ctx, ticket.Date)
+			if err != nil {
+				return settled, fmt.Errorf("failed to get result: %w", err)
+			}
+			if len(found) > 0 {
+				result = found[0]
+			}
+			results[ticket.Date] = result
+		}
+		if result == nil {
+			continue
+		}
+
+		if err := l.settle(ctx, ticket, result); err != nil {
+			return settled, err
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+func (l *ledgerService) settle(ctx context.Context, ticket *model.PlayedTicket, result *model.Result) error {
+	prize, err := ticketPrize(ticket.Ticket, result, l.prizes.For(ticket.Lottery))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ticket.Status = TicketLost
+	ticket.Payout = prize * ticket.Stake
+	if prize > 0 {
+		ticket.Status = TicketWon
+	}
+	ticket.ResultID = result.ID
+	ticket.SettledAt = &now
+
+	if err := l.ticketRepo.Settle(ctx, ticket); err != nil {
+		return fmt.Errorf("failed to settle ticket %d: %w", ticket.ID, err)
+	}
+	return nil
+}
+
+func (l *ledgerService) Tickets(ctx context.Context, filter model.TicketFilter) ([]*model.PlayedTicket, error) {
+	tickets, err := l.ticketRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// Summary resume las apuestas del filtro por usuario o por estrategia.
+func (l *ledgerService) Summary(ctx context.Context, filter model.TicketFilter, by string) (*model.LedgerReport, error) {
+	if by == "" {
+		by = LedgerByUser
+	}
+	if by != LedgerByUser && by != LedgerByStrategy {
+		return nil, fmt.Errorf("unknown summary grouping: %s", by)
+	}
+
+	tickets, err := l.Tickets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return buildLedgerReport(tickets, by), nil
+}
+
+func (l *ledgerService) validTicket(ticket *model.PlayedTicket) error {
+	if ticket.Lottery == 0 {
+		ticket.Lottery = l.resultRepo.Lottery()
+	}
+	if ticket.User == "" {
+		return fmt.Errorf("ticket user is required")
+	}
+	if ticket.Date.IsZero() {
+		return fmt.Errorf("ticket date is required")
+	}
+	ticket.Date = day(ticket.Date)
+	if ticket.Number < 0 || ticket.Number > 9999 {
+		return fmt.Errorf("invalid number: %d", ticket.Number)
+	}
+	if !validBet(ticket.Bet) {
+		return fmt.Errorf("unknown bet type: %s", ticket.Bet)
+	}
+
+	ticket.Sign = strings.ToUpper(ticket.Sign)
+	if ticket.Bet == BetFourSign && model.SignIndex(ticket.Sign) < 0 {
+		return fmt.Errorf("invalid sign %q for bet %s", ticket.Sign, ticket.Bet)
+	}
+	if ticket.Bet != BetFourSign {
+		ticket.Sign = ""
+	}
+
+	if ticket.Stake <= 0 {
+		return fmt.Errorf("invalid stake: %v", ticket.Stake)
+	}
+	return nil
+}
+
+// ticketPrize devuelve el premio por unidad apostada que paga la apuesta con el resultado.
+func ticketPrize(ticket model.Ticket, result *model.Result, table model.PrizeTable) (float64, error) {
+	drawn := result.First*1000 + result.Second*100 + result.Third*10 + result.Fourth
+	n := ticket.Number
+
+	// Cifras acertadas desde el final
+	matched := 0
+	for _, mod := range []int{10, 100, 1000, 10000} {
+		if n%mod != drawn%mod {
+			break
+		}
+		matched++
+	}
+	tiers := []string{"", BetOne, BetTwo, BetThree, BetFour}
+
+	switch ticket.Bet {
+	case BetCombined:
+		return table.Combined[tiers[matched]], nil
+	case BetFourSign:
+		if matched == 4 && ticket.Sign == result.Sign {
+			return table.Prizes[BetFourSign], nil
+		}
+		return 0, nil
+	case BetFour, BetThree, BetTwo, BetOne:
+		needed := map[string]int{BetOne: 1, BetTwo: 2, BetThree: 3, BetFour: 4}[ticket.Bet]
+		if matched >= needed {
+			return table.Prizes[ticket.Bet], nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unknown bet type: %s", ticket.Bet)
+	}
+}
+
+func buildLedgerReport(tickets []*model.PlayedTicket, by string) *model.LedgerReport {
+	report := &model.LedgerReport{By: by, Total: model.LedgerSummary{Group: "total"}}
+
+	groups := make(map[string]*model.LedgerSummary)
+	for _, ticket := range tickets {
+		key := ticket.User
+		if by == LedgerByStrategy {
+			key = ticket.Strategy
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &model.LedgerSummary{Group: key}
+			groups[key] = group
+		}
+		addToSummary(group, ticket)
+		addToSummary(&report.Total, ticket)
+	}
+
+	for _, group := range groups {
+		closeSummary(group)
+		report.Groups = append(report.Groups, *group)
+	}
+	closeSummary(&report.Total)
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Group < report.Groups[j].Group
+	})
+	return report
+}
+
+func addToSummary(summary *model.LedgerSummary, ticket *model.PlayedTicket) {
+	summary.Tickets++
+	summary.Stake += ticket.Stake
+
+	switch ticket.Status {
+	case TicketPending:
+		summary.Pending++
+		return
+	case TicketWon:
+		summary.Won++
+	case TicketLost:
+		summary.Lost++
+	}
+	summary.Settled += ticket.Stake
+	summary.Payout += ticket.Payout
+}
+
+func closeSummary(summary *model.LedgerSummary) {
+	summary.Net = summary.Payout - summary.Settled
+	if summary.Settled > 0 {
+		summary.ROI = summary.Net / summary.Settled
+	}
+}
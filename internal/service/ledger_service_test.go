@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestTicketPrize(t *testing.T) {
+	result := &model.Result{First: 1, Second: 2, Third: 3, Fourth: 4, Sign: "C"}
+	table := defaultPrizeTable
+
+	tests := []struct {
+		name   string
+		ticket model.Ticket
+		want   float64
+	}{
+		{"four exact", model.Ticket{Number: 1234, Bet: BetFour}, 4500},
+		{"four with three", model.Ticket{Number: 9234, Bet: BetFour}, 0},
+		{"three", model.Ticket{Number: 9234, Bet: BetThree}, 400},
+		{"three with the four", model.Ticket{Number: 1234, Bet: BetThree}, 400},
+		{"two", model.Ticket{Number: 9934, Bet: BetTwo}, 50},
+		{"two with the wrong last digit", model.Ticket{Number: 1235, Bet: BetTwo}, 0},
+		{"one", model.Ticket{Number: 4, Bet: BetOne}, 5},
+		{"four with sign", model.Ticket{Number: 1234, Bet: BetFourSign, Sign: "C"}, 42000},
+		{"four with wrong sign", model.Ticket{Number: 1234, Bet: BetFourSign, Sign: "A"}, 0},
+		{"combined pays the highest tier", model.Ticket{Number: 1234, Bet: BetCombined}, 2000},
+		{"combined three", model.Ticket{Number: 5234, Bet: BetCombined}, 200},
+		{"combined one", model.Ticket{Number: 5554, Bet: BetCombined}, 2},
+		{"combined nothing", model.Ticket{Number: 5555, Bet: BetCombined}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ticketPrize(tt.ticket, result, table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ticketPrize = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ticketPrize(model.Ticket{Number: 1, Bet: "five"}, result, table); err == nil {
+		t.Error("expected an error for an unknown bet")
+	}
+}
+
+func TestBuildLedgerReport(t *testing.T) {
+	tickets := []*model.PlayedTicket{
+		{User: "ana", Strategy: "pair", Ticket: model.Ticket{Stake: 2}, Status: TicketWon, Payout: 10},
+		{User: "ana", Strategy: "frequency", Ticket: model.Ticket{Stake: 1}, Status: TicketLost},
+		{User: "luis", Strategy: "pair", Ticket: model.Ticket{Stake: 3}, Status: TicketPending},
+	}
+
+	report := buildLedgerReport(tickets, LedgerByUser)
+	if len(report.Groups) != 2 || report.Groups[0].Group != "ana" {
+		t.Fatalf("groups = %+v", report.Groups)
+	}
+	ana := report.Groups[0]
+	if ana.Settled != 3 || ana.Payout != 10 || ana.Net != 7 || ana.Won != 1 || ana.Lost != 1 {
+		t.Errorf("ana = %+v", ana)
+	}
+	if report.Total.Tickets != 3 || report.Total.Pending != 1 || report.Total.Stake != 6 || report.Total.Settled != 3 {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	byStrategy := buildLedgerReport(tickets, LedgerByStrategy)
+	if len(byStrategy.Groups) != 2 || byStrategy.Groups[1].Group != "pair" || byStrategy.Groups[1].Tickets != 2 {
+		t.Errorf("strategy groups = %+v", byStrategy.Groups)
+	}
+}
+
+func TestSettleCatchesUpOnOldPendingTickets(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	results := newFakeResultRepo(21)
+	tickets := &fakeTicketRepo{results: results}
+	ledger := NewLedgerService(tickets, results, PrizeTables{})
+
+	for _, date := range []time.Time{day1, day2, day2.AddDate(0, 0, 1)} {
+		ticket := &model.PlayedTicket{User: "ana", Date: date, Ticket: model.Ticket{Number: 1234, Bet: BetFour, Stake: 1}}
+		if err := ledger.Register(ctx, ticket); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Los dos primeros sorteos llegan juntos, como si el scrapping anterior no hubiera liquidado
+	results.Create(ctx, &model.Result{Date: day1, First: 1, Second: 2, Third: 3, Fourth: 4})
+	results.Create(ctx, &model.Result{Date: day2, First: 9, Second: 9, Third: 9, Fourth: 9})
+
+	settled, err := ledger.Settle(ctx, 21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settled != 2 {
+		t.Errorf("settled %d tickets, want 2", settled)
+	}
+
+	want := []string{TicketWon, TicketLost, TicketPending}
+	for i, ticket := range tickets.tickets {
+		if ticket.Status != want[i] {
+			t.Errorf("ticket %d is %s, want %s", ticket.ID, ticket.Status, want[i])
+		}
+	}
+	if tickets.tickets[0].Payout != 4500 {
+		t.Errorf("payout = %v, want 4500", tickets.tickets[0].Payout)
+	}
+
+	if settled, _ := ledger.Settle(ctx, 21); settled != 0 {
+		t.Errorf("second settle liquidated %d tickets again", settled)
+	}
+}
+
+func TestRegisterKeepsTheTicketWhenSettlementFails(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	results := newFakeResultRepo(21, &model.Result{Date: date, First: 1, Second: 2, Third: 3, Fourth: 4})
+	tickets := &fakeTicketRepo{results: results, failSettle: true}
+	ledger := NewLedgerService(tickets, results, PrizeTables{})
+
+	ticket := &model.PlayedTicket{User: "ana", Date: date, Ticket: model.Ticket{Number: 1234, Bet: BetFour, Stake: 1}}
+	if err := ledger.Register(ctx, ticket); err != nil {
+		t.Fatalf("Register returned %v after creating the ticket", err)
+	}
+	if ticket.ID == 0 || ticket.Status != TicketPending {
+		t.Errorf("ticket = %+v, want it created and pending", ticket)
+	}
+	if len(tickets.tickets) != 1 {
+		t.Fatalf("%d tickets saved, want 1", len(tickets.tickets))
+	}
+
+	// El próximo Settle la liquida
+	tickets.failSettle = false
+	if settled, err := ledger.Settle(ctx, 21); err != nil || settled != 1 {
+		t.Errorf("Settle = %d, %v; want 1 ticket", settled, err)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// Modos de selección de los mejores números. El análisis original se quedaba con los 100 totales más
+// bajos, es decir, las combinaciones menos frecuentes ("frías").
+const (
+	ModeCold     = "cold"     // menor score primero: combinaciones que menos salen
+	ModeHot      = "hot"      // mayor score primero: combinaciones que más salen
+	ModeDistance = "distance" // más cerca del score medio primero: números "típicos"
+)
+
+// SelectionModes son los modos que se calculan en cada análisis, el primero es el de por defecto.
+var SelectionModes = []string{ModeCold, ModeHot, ModeDistance}
+
+func validMode(mode string) error {
+	for _, m := range SelectionModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown selection mode: %s", mode)
+}
+
+// modeKeys transforma los scores en claves de orden ascendente según el modo, así la selección y
+// los ordenamientos siempre se quedan con la clave más baja.
+func modeKeys(values []float64, mode string) []float64 {
+	keys := make([]float64, len(values))
+
+	switch mode {
+	case ModeHot:
+		for i, v := range values {
+			keys[i] = -v
+		}
+	case ModeDistance:
+		mean := 0.0
+		for _, v := range values {
+			mean += v
+		}
+		if len(values) > 0 {
+			mean /= float64(len(values))
+		}
+		for i, v := range values {
+			keys[i] = math.Abs(v - mean)
+		}
+	default:
+		copy(keys, values)
+	}
+
+	return keys
+}
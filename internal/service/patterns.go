@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// Clases de patrón de un número de cuatro cifras. Un número puede pertenecer a varias
+// (4554 es palíndromo y dos pares).
+const (
+	PatternDistinct   = "distinct"   // cuatro dígitos distintos
+	PatternRepeated   = "repeated"   // algún dígito repetido
+	PatternDouble     = "double"     // exactamente un par: 1123
+	PatternTwoPairs   = "two_pairs"  // 1122, 1212, 4554
+	PatternTriple     = "triple"     // 1112
+	PatternQuadruple  = "quadruple"  // 7777
+	PatternAscending  = "ascending"  // escalera: 1234
+	PatternDescending = "descending" // escalera descendente: 4321
+	PatternPalindrome = "palindrome" // espejo: 4554
+)
+
+// PatternClasses lista las clases en el orden en que se reportan.
+var PatternClasses = []string{
+	PatternDistinct, PatternRepeated, PatternDouble, PatternTwoPairs, PatternTriple,
+	PatternQuadruple, PatternAscending, PatternDescending, PatternPalindrome,
+}
+
+// ClassifyNumber devuelve las clases de patrón a las que pertenece el número.
+func ClassifyNumber(number int) []string {
+	d := utils.Digits(number)
+
+	var occurrences [10]int
+	for _, digit := range d {
+		occurrences[digit]++
+	}
+
+	pairs, maxRepeat := 0, 0
+	for _, n := range occurrences {
+		if n == 2 {
+			pairs++
+		}
+		if n > maxRepeat {
+			maxRepeat = n
+		}
+	}
+
+	var classes []string
+	switch {
+	case maxRepeat == 1:
+		classes = append(classes, PatternDistinct)
+	case maxRepeat == 4:
+		classes = append(classes, PatternRepeated, PatternQuadruple)
+	case maxRepeat == 3:
+		classes = append(classes, PatternRepeated, PatternTriple)
+	case pairs == 2:
+		classes = append(classes, PatternRepeated, PatternTwoPairs)
+	default:
+		classes = append(classes, PatternRepeated, PatternDouble)
+	}
+
+	if d[1] == d[0]+1 && d[2] == d[1]+1 && d[3] == d[2]+1 {
+		classes = append(classes, PatternAscending)
+	}
+	if d[1] == d[0]-1 && d[2] == d[1]-1 && d[3] == d[2]-1 {
+		classes = append(classes, PatternDescending)
+	}
+	if d[0] == d[3] && d[1] == d[2] {
+		classes = append(classes, PatternPalindrome)
+	}
+
+	return classes
+}
+
+// numberHasPattern indica si el número pertenece a la clase.
+func numberHasPattern(number int, class string) bool {
+	for _, c := range ClassifyNumber(number) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePatterns valida y ordena una lista de clases separadas por coma.
+func parsePatterns(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	var classes []string
+	for _, class := range strings.Split(list, ",") {
+		class = strings.TrimSpace(class)
+		valid := false
+		for _, known := range PatternClasses {
+			if class == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown pattern class: %s", class)
+		}
+		classes = append(classes, class)
+	}
+
+	sort.Strings(classes)
+	return classes, nil
+}
+
+// buildPatternReport compara la frecuencia histórica de cada clase con su peso teórico dentro de los
+// 10.000 números, su evolución por año y cuántos sorteos lleva sin salir. Los sorteos deben venir
+// ordenados por fecha.
+func buildPatternReport(draws []draw) *model.PatternReport {
+	report := &model.PatternReport{TotalDraws: len(draws)}
+
+	theoretical := make(map[string]int, len(PatternClasses))
+	for number := 0; number < 10000; number++ {
+		for _, class := range ClassifyNumber(number) {
+			theoretical[class]++
+		}
+	}
+
+	for _, class := range PatternClasses {
+		stat := model.PatternStat{
+			Class:            class,
+			TheoreticalShare: float64(theoretical[class]) / 10000,
+			GapDraws:         -1,
+		}
+
+		var trend []model.PatternTrend
+		for i, dr := range draws {
+			year := dr.date.Year()
+			if len(trend) == 0 || trend[len(trend)-1].Year != year {
+				trend = append(trend, model.PatternTrend{Year: year})
+			}
+			trend[len(trend)-1].Draws++
+
+			r := dr.result
+			if numberHasPattern(r.First*1000+r.Second*100+r.Third*10+r.Fourth, class) {
+				stat.Hits++
+				trend[len(trend)-1].Hits++
+				lastSeen := dr.date
+				stat.LastSeen = &lastSeen
+				stat.GapDraws = len(draws) - 1 - i
+			}
+		}
+
+		for i := range trend {
+			trend[i].Share = float64(trend[i].Hits) / float64(trend[i].Draws)
+		}
+		stat.Trend = trend
+
+		if len(draws) > 0 {
+			stat.ObservedShare = float64(stat.Hits) / float64(len(draws))
+			stat.Ratio = stat.ObservedShare / stat.TheoreticalShare
+		}
+
+		report.Classes = append(report.Classes, stat)
+	}
+
+	return report
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestClassifyNumber(t *testing.T) {
+	tests := []struct {
+		number int
+		want   []string
+	}{
+		{1234, []string{PatternDistinct, PatternAscending}},
+		{4321, []string{PatternDistinct, PatternDescending}},
+		{1221, []string{PatternRepeated, PatternTwoPairs, PatternPalindrome}},
+		{1212, []string{PatternRepeated, PatternTwoPairs}},
+		{1123, []string{PatternRepeated, PatternDouble}},
+		{1112, []string{PatternRepeated, PatternTriple}},
+		{7777, []string{PatternRepeated, PatternQuadruple, PatternPalindrome}},
+		{2057, []string{PatternDistinct}},
+		{27, []string{PatternRepeated, PatternDouble}},     // 0027
+		{123, []string{PatternDistinct, PatternAscending}}, // 0123
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%04d", tt.number), func(t *testing.T) {
+			if got := ClassifyNumber(tt.number); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ClassifyNumber(%04d) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternClassSizes(t *testing.T) {
+	want := map[string]int{
+		PatternDistinct:   5040,
+		PatternRepeated:   4960,
+		PatternDouble:     4320,
+		PatternTwoPairs:   270,
+		PatternTriple:     360,
+		PatternQuadruple:  10,
+		PatternAscending:  7,
+		PatternDescending: 7,
+		PatternPalindrome: 100,
+	}
+
+	got := make(map[string]int)
+	for number := 0; number < 10000; number++ {
+		for _, class := range ClassifyNumber(number) {
+			got[class]++
+		}
+	}
+	for class, n := range want {
+		if got[class] != n {
+			t.Errorf("%s has %d numbers, want %d", class, got[class], n)
+		}
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"blank", "  ", nil, false},
+		{"one", "triple", []string{"triple"}, false},
+		{"sorted and trimmed", " palindrome, ascending ", []string{"ascending", "palindrome"}, false},
+		{"unknown", "triple,fivefold", nil, true},
+		{"empty item", "triple,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePatterns(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parsePatterns(%q) = %v, want %v", tt.list, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPatternReport(t *testing.T) {
+	draws := testDraws("2023-12-30", "2023-12-31", "2024-01-01", "2024-01-02")
+	for i, number := range []int{7777, 1234, 1123, 5678} {
+		d := [4]int{number / 1000, number / 100 % 10, number / 10 % 10, number % 10}
+		draws[i].result = &model.Result{Date: draws[i].date, First: d[0], Second: d[1], Third: d[2], Fourth: d[3]}
+	}
+
+	report := buildPatternReport(draws)
+	stats := make(map[string]model.PatternStat)
+	for _, stat := range report.Classes {
+		stats[stat.Class] = stat
+	}
+
+	tests := []struct {
+		class     string
+		hits, gap int
+		trend     int // sorteos con la clase en 2024
+	}{
+		{PatternQuadruple, 1, 3, 0},
+		{PatternAscending, 2, 0, 1},
+		{PatternDouble, 1, 1, 1},
+		{PatternDescending, 0, -1, 0},
+	}
+	for _, tt := range tests {
+		stat := stats[tt.class]
+		if stat.Hits != tt.hits || stat.GapDraws != tt.gap {
+			t.Errorf("%s: %d hits, gap %d, want %d hits, gap %d", tt.class, stat.Hits, stat.GapDraws, tt.hits, tt.gap)
+		}
+		if len(stat.Trend) != 2 || stat.Trend[1].Year != 2024 || stat.Trend[1].Hits != tt.trend {
+			t.Errorf("%s: trend %+v, want %d hits in 2024", tt.class, stat.Trend, tt.trend)
+		}
+	}
+
+	if got := stats[PatternQuadruple].Ratio; got != 0.25/0.001 {
+		t.Errorf("quadruple ratio = %v, want %v", got, 0.25/0.001)
+	}
+}
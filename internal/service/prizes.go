@@ -0,0 +1,129 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lottery-analyzer/internal/model"
+)
+
+// Tipos de apuesta. Las de cifras pagan si coinciden las últimas cifras del número sorteado.
+const (
+	BetFour     = "four"      // las cuatro cifras
+	BetThree    = "three"     // últimas tres
+	BetTwo      = "two"       // últimas dos
+	BetOne      = "one"       // última
+	BetFourSign = "four_sign" // las cuatro cifras y el signo
+	BetCombined = "combined"  // paga el mayor nivel acertado entre four, three, two y one
+)
+
+// BetTypes son todos los tipos de apuesta.
+var BetTypes = []string{BetFour, BetThree, BetTwo, BetOne, BetFourSign, BetCombined}
+
+// defaultPrizeTable son pagos de referencia de un chance con signo, por unidad apostada; se usan
+// para las loterías que no están en el archivo de premios.
+var defaultPrizeTable = model.PrizeTable{
+	Prizes: map[string]float64{
+		BetFour:     4500,
+		BetThree:    400,
+		BetTwo:      50,
+		BetOne:      5,
+		BetFourSign: 42000,
+	},
+	Combined: map[string]float64{
+		BetFour:  2000,
+		BetThree: 200,
+		BetTwo:   20,
+		BetOne:   2,
+	},
+}
+
+// PrizeTables son las tablas de premios por lotería.
+type PrizeTables map[int]model.PrizeTable
+
+// LoadPrizeTables lee un archivo JSON con una lista de tablas de premios; sin archivo todas las
+// loterías usan la tabla de referencia.
+func LoadPrizeTables(path string) (PrizeTables, error) {
+	tables := PrizeTables{}
+	if path == "" {
+		return tables, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prize tables: %w", err)
+	}
+
+	var list []model.PrizeTable
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse prize tables: %w", err)
+	}
+
+	for _, table := range list {
+		for bet := range table.Prizes {
+			if !validBet(bet) || bet == BetCombined {
+				return nil, fmt.Errorf("lottery %d: unknown bet type %s", table.Lottery, bet)
+			}
+		}
+		tables[table.Lottery] = table
+	}
+	return tables, nil
+}
+
+// For devuelve la tabla de la lotería o la de referencia.
+func (t PrizeTables) For(lottery int) model.PrizeTable {
+	if table, ok := t[lottery]; ok {
+		return table
+	}
+	table := defaultPrizeTable
+	table.Lottery = lottery
+	return table
+}
+
+func validBet(bet string) bool {
+	for _, b := range BetTypes {
+		if bet == b {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTickets lee apuestas con formato número:apuesta[:signo][:valor].
+func ParseTickets(entries []string) ([]model.Ticket, error) {
+	var tickets []model.Ticket
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid ticket: %s", entry)
+		}
+
+		number, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields[0]) > 4 || number < 0 {
+			return nil, fmt.Errorf("invalid ticket number: %s", fields[0])
+		}
+		ticket := model.Ticket{Number: number, Bet: fields[1]}
+
+		for _, field := range fields[2:] {
+			if stake, err := strconv.ParseFloat(field, 64); err == nil {
+				ticket.Stake = stake
+			} else {
+				ticket.Sign = strings.ToUpper(field)
+			}
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("no tickets to value")
+	}
+	return tickets, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"math"
+
+	"lottery-analyzer/pkg/utils"
+)
+
+const defaultTemperature = 1.0
+
+// modeledProbabilities convierte los scores en una distribución sobre los 10.000 números: softmax de
+// las claves del modo estandarizadas, así el mejor número según el modo es el más probable. Con
+// temperatura 0 la distribución es uniforme; cuanto más alta, más peso a los primeros del ranking.
+func modeledProbabilities(scores []float64, mode string, temperature float64) []float64 {
+	keys := modeKeys(scores, mode)
+	mean, std := utils.MeanStd(keys)
+
+	probabilities := make([]float64, len(keys))
+	if std == 0 || temperature <= 0 {
+		for i := range probabilities {
+			probabilities[i] = 1 / float64(len(keys))
+		}
+		return probabilities
+	}
+
+	// Se resta el máximo exponente para que exp no se desborde
+	maxExponent := math.Inf(-1)
+	for i, key := range keys {
+		probabilities[i] = -temperature * (key - mean) / std
+		maxExponent = math.Max(maxExponent, probabilities[i])
+	}
+
+	total := 0.0
+	for i := range probabilities {
+		probabilities[i] = math.Exp(probabilities[i] - maxExponent)
+		total += probabilities[i]
+	}
+	for i := range probabilities {
+		probabilities[i] /= total
+	}
+	return probabilities
+}
@@ -5,129 +5,497 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
-	"strconv"
+	"sync"
 	"time"
 
 	"lottery-analyzer/internal/model"
 	"lottery-analyzer/internal/repository"
+	"lottery-analyzer/pkg/utils"
 )
 
 type processorService struct {
 	scrapperService ScrapperService
 	resultRepo      repository.ResultRepository
+	prizes          PrizeTables
+	cache           *analysisCache
+	flight          *analysisFlight
+
+	scrapeMu   sync.Mutex
+	lastScrape time.Time
 }
 
-func NewProcessorService(scrapper ScrapperService, resultRepo repository.ResultRepository) ProcessorService {
-	return &processorService{
+func NewProcessorService(scrapper ScrapperService, resultRepo repository.ResultRepository, prizes PrizeTables) ProcessorService {
+	p := &processorService{
 		scrapperService: scrapper,
 		resultRepo:      resultRepo,
+		prizes:          prizes,
+		cache:           newAnalysisCache(),
+		flight:          newAnalysisFlight(),
 	}
+
+	// Un sorteo nuevo deja viejos todos los análisis en memoria
+	scrapper.OnNewResult(func(*model.Result) {
+		p.cache.invalidate()
+	})
+	return p
 }
 
-func (p *processorService) ProcessAnalysis(ctx context.Context) (*model.Analysis, error) {
+func (p *processorService) ProcessAnalysis(ctx context.Context, params model.AnalysisParams) (*model.Analysis, error) {
 	start := time.Now()
+	params = normalizeParams(params)
+	if err := validMode(params.Mode); err != nil {
+		return nil, err
+	}
+	_, cutoff, err := analysisDates(params)
+	if err != nil {
+		return nil, err
+	}
 
 	fmt.Printf("Analysis on: %s\n", start.Format(time.DateTime))
 
-	shouldAnalyze, err := p.resultRepo.ShouldAnalyzeDate(ctx, time.Now())
+	// Las llamadas simultáneas con los mismos parámetros comparten un único cálculo
+	analysis, err := p.flight.do(ctx, paramsHash(params), func() (interface{}, error) {
+		return p.currentAnalysis(ctx, params, cutoff, start)
+	})
 	if err != nil {
-		// manejar error
+		return nil, err
 	}
-	if shouldAnalyze {
 
-		// 1. Ejecutar scrapping
-		if err := p.scrapperService.ScrapingFromLastDate(ctx); err != nil {
-			return nil, fmt.Errorf("scrapping failed: %w", err)
-		}
+	return withMode(analysis.(*model.Analysis), params.Mode), nil
+}
 
-		// 2. Secuencia Fibonacci para fechas y encontrar frecuencias
-		frequencyData, fibCalcuCount, err := CalculateFrequencies(ctx, p.resultRepo)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate frequencies: %w", err)
-		}
+// currentAnalysis devuelve el análisis de los datos actuales: de memoria, de la base de datos o
+// calculado. Con varias instancias un lock de la base de datos hace que sólo una lo calcule.
+func (p *processorService) currentAnalysis(ctx context.Context, params model.AnalysisParams, cutoff, start time.Time) (*model.Analysis, error) {
+	// 1. Ejecutar scrapping antes de mirar los datos, así un sorteo nuevo invalida el análisis guardado;
+	// dentro del intervalo entre scrappings se usa directamente lo que ya está en memoria o guardado
+	if err := p.refresh(ctx, params); err != nil {
+		return nil, err
+	}
 
-		if frequencyData == nil {
-			return nil, fmt.Errorf("frequency data is nil")
-		}
+	// Un análisis guardado sólo sirve con la misma estrategia, parámetros, día y sorteos
+	fingerprint, err := p.resultRepo.Fingerprint(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data fingerprint: %w", err)
+	}
+	key := analysisKey(params, *fingerprint)
 
-		// 3. Calcular probabilidades y encontrar mejores números
-		bestNumbers := make([]int, 100)
-		bestScores := make([]float64, 100)
+	if analysis := p.cache.get(key); analysis != nil {
+		return analysis, nil
+	}
 
-		// Inicializar con valores altos
-		for i := range bestScores {
-			bestScores[i] = 10000.0
-			bestNumbers[i] = 1000 - i
-		}
+	analysis, err := p.storedAnalysis(ctx, key)
+	if err != nil || analysis != nil {
+		return analysis, err
+	}
 
-		for number := 0; number < 10000; number++ {
-			score := p.calculateProbabilityResult(number, frequencyData)
+	unlock, err := p.resultRepo.Lock(ctx, "analysis:"+key.ParamsHash[:32], analysisLockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock analysis: %w", err)
+	}
+	defer unlock()
 
-			// Si el score es mejor que el peor de los mejores
-			if bestScores[99] > score {
-				bestScores[99] = score
-				bestNumbers[99] = number
+	// Otra instancia pudo guardarlo mientras se esperaba el lock
+	analysis, err = p.storedAnalysis(ctx, key)
+	if err != nil || analysis != nil {
+		return analysis, err
+	}
 
-				// Ordenar y reorganizar
-				p.sortBestNumbers(bestNumbers, bestScores)
-			}
-		}
+	analysis, err = p.analyze(ctx, params, key, start)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.put(key, analysis)
+	return analysis, nil
+}
 
-		// 4. Calcular números que no han caído nunca
-		unplayedCount, err := p.UnplayedNumbers(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get unplayed numbers: %w", err)
-		}
+// storedAnalysis busca en la base de datos el último análisis con la clave y lo deja en memoria.
+func (p *processorService) storedAnalysis(ctx context.Context, key model.AnalysisKey) (*model.Analysis, error) {
+	record, err := p.resultRepo.LastAnalysis(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last analysis: %w", err)
+	}
+	if record == nil {
+		return nil, nil
+	}
 
-		// 5. Serializar análisis y guardar en la base de datos así no se vuelve a calcular
+	analysis, err := savedAnalysis(record)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.put(key, analysis)
+	return analysis, nil
+}
 
-		analysis := &model.Analysis{
-			BestNumbers:       bestNumbers,
-			BestScores:        bestScores,
-			TotalProcessed:    10000,
-			GroupDaysAnalyzed: fibCalcuCount,
-			ExecutionTime:     time.Since(start).String(),
-			Timestamp:         time.Now(),
-			UnplayedCount:     unplayedCount,
+func (p *processorService) analyze(ctx context.Context, params model.AnalysisParams, key model.AnalysisKey, start time.Time) (*model.Analysis, error) {
+	// 2-3. Frecuencias y scores de todos los números
+	space, err := p.cachedSpace(ctx, params, key.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Encontrar mejores números entre los que pasan el filtro, en todos los modos de selección
+	reportStep(ctx, StepRanking)
+	filter, err := newNumberFilter(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ensemble, _ := space.strategy.(*ensembleStrategy)
+	baselines, err := p.holdoutBaselines(ctx, params, space.history, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := make([]model.Ranking, 0, len(SelectionModes))
+	for _, mode := range SelectionModes {
+		// 5. Mejores signos y combinaciones número+signo del modo
+		ranking := p.buildRanking(mode, space.modeScores(mode), filter, space.frequencies, params.TopNumbers)
+
+		// 6. Comparar contra selecciones aleatorias el ranking armado sin los últimos sorteos
+		ranking.Baseline = baselines[mode]
+
+		if ensemble != nil {
+			ranking.Ensemble = ensemble.report(ranking.Numbers, mode)
 		}
+		rankings = append(rankings, ranking)
+	}
+
+	// 7. Estabilidad de los rankings remuestreando el histórico
+	reportStep(ctx, StepBootstrap)
+	if err := p.bootstrapStability(ctx, params, space.history, space.asOf, filter, rankings); err != nil {
+		return nil, fmt.Errorf("bootstrap failed: %w", err)
+	}
+	primary := rankingFor(rankings, params.Mode)
+
+	// 8. Calcular números que no han caído nunca hasta el corte
+	unplayedCount := unplayedFrom(space.history)
+
+	// 9. Serializar análisis y guardar en la base de datos así no se vuelve a calcular
+	reportStep(ctx, StepSaving)
+
+	analysis := &model.Analysis{
+		BestNumbers:       primary.Numbers,
+		BestScores:        primary.Scores,
+		BestSigns:         primary.Signs,
+		BestSignScores:    primary.SignScores,
+		BestCombinations:  primary.Combinations,
+		TotalProcessed:    10000,
+		GroupDaysAnalyzed: space.windows,
+		ExecutionTime:     time.Since(start).String(),
+		Timestamp:         space.asOf,
+		UnplayedCount:     unplayedCount,
+		Params:            params,
+		Baseline:          primary.Baseline,
+		Mode:              params.Mode,
+		Rankings:          rankings,
+		Stability:         primary.Stability,
+		Ensemble:          primary.Ensemble,
+		Key:               key,
+	}
+
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seriayze analysis: %w", err)
+	}
+
+	// Las tablas de frecuencias completas se guardan aparte del JSON, en binario comprimido
+	frequencies, err := encodeFrequencies(space.frequencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode frequencies: %w", err)
+	}
+
+	id, err := p.resultRepo.SaveAnalysis(ctx, key, &data, frequencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+	analysis.ID = id
+
+	return analysis, nil
+}
+
+// scoredSpace son las frecuencias y los scores de los 10.000 números a la fecha del análisis.
+type scoredSpace struct {
+	asOf        time.Time
+	frequencies *model.FrequencyData
+	windows     int
+	history     []*model.Result
+	strategy    Strategy
+	scores      []float64
+}
+
+// modeScores son los scores con los que se ordena el modo.
+func (s *scoredSpace) modeScores(mode string) []float64 {
+	return scoresFor(s.strategy, s.scores, mode)
+}
+
+// refresh ejecuta el scrapping; sólo hace falta si el análisis es de hoy y no se scrapeó en el
+// último scrapeInterval, porque los sorteos salen pocas veces por día.
+func (p *processorService) refresh(ctx context.Context, params model.AnalysisParams) error {
+	if params.AsOf != today() {
+		return nil
+	}
+
+	// Las llamadas simultáneas esperan al scrapping en curso en vez de repetirlo
+	p.scrapeMu.Lock()
+	defer p.scrapeMu.Unlock()
+	if time.Since(p.lastScrape) < scrapeInterval {
+		return nil
+	}
+	reportStep(ctx, StepScraping)
+
+	// Con varias instancias scrapea una a la vez, así no se guarda dos veces el mismo sorteo
+	unlock, err := p.resultRepo.Lock(ctx, "scrape", scrapeLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock scrapping: %w", err)
+	}
+	defer unlock()
+
+	if err := p.scrapperService.ScrapingFromLastDate(ctx); err != nil {
+		return fmt.Errorf("scrapping failed: %w", err)
+	}
+	p.lastScrape = time.Now()
+	return nil
+}
 
-		data, err := json.Marshal(analysis)
+// currentSpace devuelve los scores de los datos actuales, de memoria o calculados.
+func (p *processorService) currentSpace(ctx context.Context, params model.AnalysisParams) (*scoredSpace, error) {
+	if err := p.refresh(ctx, params); err != nil {
+		return nil, err
+	}
+	_, cutoff, err := analysisDates(params)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := p.resultRepo.Fingerprint(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data fingerprint: %w", err)
+	}
+	return p.cachedSpace(ctx, params, *fingerprint)
+}
+
+// cachedSpace devuelve los scores guardados en memoria para la huella de datos o los calcula; las
+// llamadas simultáneas con la misma clave comparten el cálculo. Los scores no se modifican después.
+func (p *processorService) cachedSpace(ctx context.Context, params model.AnalysisParams, fingerprint model.DataFingerprint) (*scoredSpace, error) {
+	key := spaceKey(params, fingerprint)
+	if space := p.cache.space(key); space != nil {
+		return space, nil
+	}
+
+	space, err := p.flight.do(ctx, "space:"+key.ParamsHash, func() (interface{}, error) {
+		space, err := p.scoreSpace(ctx, params)
 		if err != nil {
-			return nil, fmt.Errorf("failed to seriayze analysis: %w", err)
+			return nil, err
 		}
+		p.cache.putSpace(key, space)
+		return space, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return space.(*scoredSpace), nil
+}
 
-		if err := p.resultRepo.SaveAnalysis(ctx, &data); err != nil {
-			return nil, fmt.Errorf("failed to save analysis: %w", err)
-		}
+// scoreSpace puntúa todos los números con la estrategia de params, sin sorteos posteriores al corte;
+// los datos tienen que estar al día (refresh).
+func (p *processorService) scoreSpace(ctx context.Context, params model.AnalysisParams) (*scoredSpace, error) {
+	asOf, cutoff, err := analysisDates(params)
+	if err != nil {
+		return nil, err
+	}
 
-		return analysis, nil
-	} else {
-		// recuperar análisis existente
-		data, err := p.resultRepo.LastAnalysis(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get last analysis: %w", err)
+	// 2. Secuencia Fibonacci para fechas y encontrar frecuencias, sin sorteos posteriores al corte;
+	// se parte de los conteos guardados y sólo se aplican los sorteos que cambiaron
+	reportStep(ctx, StepFrequencies)
+	frequencyData, fibCalcuCount, err := IncrementalFrequencies(ctx, p.resultRepo, asOf, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate frequencies: %w", err)
+	}
+
+	if frequencyData == nil {
+		return nil, fmt.Errorf("frequency data is nil")
+	}
+
+	// 3. Puntuar todos los números con la estrategia elegida
+	strategy, err := NewStrategy(params)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := p.resultRepo.BetweenDates(ctx, time.Time{}, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+
+	scores, err := strategy.Scores(ctx, &StrategyInput{Frequencies: frequencyData, History: history, AsOf: asOf})
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s failed: %w", strategy.Name(), err)
+	}
+
+	return &scoredSpace{
+		asOf:        asOf,
+		frequencies: frequencyData,
+		windows:     fibCalcuCount,
+		history:     history,
+		strategy:    strategy,
+		scores:      scores,
+	}, nil
+}
+
+func (p *processorService) BestNumbers(ctx context.Context, params model.AnalysisParams, limit int) (*model.BestNumbers, error) {
+	analysis, err := p.ProcessAnalysis(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todos los modos se calculan en el mismo análisis; los guardados antes de existir los modos
+	// sólo tienen el ranking en frío
+	ranking := model.Ranking{
+		Mode:         ModeCold,
+		Numbers:      analysis.BestNumbers,
+		Scores:       analysis.BestScores,
+		Signs:        analysis.BestSigns,
+		SignScores:   analysis.BestSignScores,
+		Combinations: analysis.BestCombinations,
+		Baseline:     analysis.Baseline,
+	}
+	if len(analysis.Rankings) > 0 {
+		ranking = rankingFor(analysis.Rankings, normalizeParams(params).Mode)
+	}
+
+	if limit > len(ranking.Numbers) {
+		limit = len(ranking.Numbers)
+	}
+
+	combinationLimit := limit
+	if combinationLimit > len(ranking.Combinations) {
+		combinationLimit = len(ranking.Combinations)
+	}
+
+	best := &model.BestNumbers{
+		Strategy:     analysis.Params.Strategy,
+		Mode:         ranking.Mode,
+		Numbers:      ranking.Numbers[:limit],
+		Scores:       ranking.Scores[:limit],
+		Signs:        ranking.Signs,
+		SignScores:   ranking.SignScores,
+		Combinations: ranking.Combinations[:combinationLimit],
+		Baseline:     ranking.Baseline,
+	}
+
+	// La estabilidad va junto a cada número; los rankings sin bootstrap no la tienen
+	best.Entries = make([]model.RankedNumber, limit)
+	for i := range best.Entries {
+		best.Entries[i] = model.RankedNumber{Rank: i + 1, Number: ranking.Numbers[i], Score: ranking.Scores[i]}
+		if i < len(ranking.Stability) {
+			stability := ranking.Stability[i]
+			best.Entries[i].Stability = &stability
 		}
+	}
 
-		var analysis model.Analysis
-		if err := json.Unmarshal(data, &analysis); err != nil {
-			return nil, err
+	// Los análisis guardados antes de ajustar el ensemble por modo sólo tienen el reporte principal
+	report := ranking.Ensemble
+	if report == nil {
+		report = analysis.Ensemble
+	}
+	if report != nil {
+		ensemble := *report
+		if len(ensemble.Contributions) > limit {
+			ensemble.Contributions = ensemble.Contributions[:limit]
 		}
+		best.Ensemble = &ensemble
+	}
 
-		return &analysis, nil
+	return best, nil
+}
+
+// Wheel elige Budget números que cubran la mayor probabilidad modelada de terminaciones de tres y
+// dos cifras, con los scores de la estrategia y el modo de params.
+func (p *processorService) Wheel(ctx context.Context, params model.AnalysisParams, wheel model.WheelParams) (*model.WheelResult, error) {
+	params = normalizeParams(params)
+	if err := validMode(params.Mode); err != nil {
+		return nil, err
 	}
+
+	if wheel.Budget <= 0 {
+		wheel.Budget = defaultWheelBudget
+	}
+	if wheel.Budget > maxWheelBudget {
+		wheel.Budget = maxWheelBudget
+	}
+	if wheel.Temperature <= 0 {
+		wheel.Temperature = defaultTemperature
+	}
+	if wheel.ThreeWeight <= 0 && wheel.TwoWeight <= 0 {
+		wheel.ThreeWeight, wheel.TwoWeight = 1, 1
+	}
+
+	space, err := p.currentSpace(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newNumberFilter(params)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := space.modeScores(params.Mode)
+	top, _ := p.rankNumbers(modeKeys(scores, params.Mode), filter, wheel.Budget)
+
+	result, err := optimizeWheel(ctx, wheel, scores, params.Mode, filter, top)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = space.strategy.Name()
+	return result, nil
 }
 
-func (p *processorService) BestNumbers(ctx context.Context, limit int) ([]int, []float64, error) {
-	analysis, err := p.ProcessAnalysis(ctx)
+// ExpectedValue valora las apuestas con la probabilidad modelada del modo y con la uniforme, usando
+// la tabla de premios de la lotería. Las apuestas con signo que no lo traen usan el mejor signo del modo.
+func (p *processorService) ExpectedValue(ctx context.Context, params model.AnalysisParams, tickets []model.Ticket, temperature float64) (*model.ExpectedValueReport, error) {
+	params = normalizeParams(params)
+	if err := validMode(params.Mode); err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("no tickets to value")
+	}
+	if temperature <= 0 {
+		temperature = defaultTemperature
+	}
+
+	space, err := p.currentSpace(ctx, params)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	if limit > len(analysis.BestNumbers) {
-		limit = len(analysis.BestNumbers)
+	signs, _ := p.rankSigns(space.frequencies, params.Mode)
+	valued := make([]model.Ticket, len(tickets))
+	for i, ticket := range tickets {
+		if !validBet(ticket.Bet) {
+			return nil, fmt.Errorf("unknown bet type: %s", ticket.Bet)
+		}
+		if ticket.Bet == BetFourSign && ticket.Sign == "" {
+			ticket.Sign = signs[0]
+		}
+		valued[i] = ticket
 	}
 
-	return analysis.BestNumbers[:limit], analysis.BestScores[:limit], nil
+	modeled := newOutcomeModel(
+		modeledProbabilities(space.modeScores(params.Mode), params.Mode, temperature),
+		modeledProbabilities(space.frequencies.SignFreq.Signs, params.Mode, temperature),
+	)
+
+	report, err := ticketValues(valued, p.prizes.For(p.resultRepo.Lottery()), modeled)
+	if err != nil {
+		return nil, err
+	}
+	report.Strategy = space.strategy.Name()
+	report.Mode = params.Mode
+	report.Temperature = temperature
+	return report, nil
 }
 
 func (p *processorService) UnplayedNumbers(ctx context.Context) (int, error) {
@@ -151,39 +519,317 @@ func (p *processorService) UnplayedNumbers(ctx context.Context) (int, error) {
 	return len(universe), nil
 }
 
-func (p *processorService) CalculateProbability(ctx context.Context, number int) (float64, error) {
-	// Implementación simplificada
-	return 0.0, nil
-}
+// CalculateProbability explica el score de un número con la estrategia, el modo y la fecha de
+// params, los mismos que usa BestNumbers: su puesto en el orden del modo entre los números que pasan
+// el filtro y el desglose de los 15 componentes de frecuencia a esa fecha.
+func (p *processorService) CalculateProbability(ctx context.Context, params model.AnalysisParams, number int) (*model.NumberScore, error) {
+	if number < 0 || number > 9999 {
+		return nil, fmt.Errorf("invalid number: %d", number)
+	}
+	params = normalizeParams(params)
+	if err := validMode(params.Mode); err != nil {
+		return nil, err
+	}
+
+	space, err := p.currentSpace(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newNumberFilter(params)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := space.modeScores(params.Mode)
+	order, _ := p.rankNumbers(modeKeys(scores, params.Mode), filter, len(scores))
+	rank := 0
+	for i, other := range order {
+		if other == number {
+			rank = i + 1
+			break
+		}
+	}
 
-func (p *processorService) Statistics(ctx context.Context) (*model.Statistics, error) {
-	// Implementación básica de estadísticas
-	count, err := p.resultRepo.Count(ctx)
+	draws, err := newDraws(space.history)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.Statistics{
-		TotalResults: count,
-		DateRange: model.DateRange{
-			StartDate: time.Now().AddDate(-1, 0, 0),
-			EndDate:   time.Now(),
-		},
+	return &model.NumberScore{
+		Number:     number,
+		Strategy:   space.strategy.Name(),
+		Mode:       params.Mode,
+		AsOf:       params.AsOf,
+		Score:      scores[number],
+		Rank:       rank,
+		Total:      len(order),
+		Components: scoreBreakdown(number, draws, space.asOf, space.frequencies),
 	}, nil
 }
 
+func (p *processorService) Statistics(ctx context.Context, startDate, endDate time.Time) (*model.Statistics, error) {
+	results, err := p.resultRepo.BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStatistics(draws), nil
+}
+
+func (p *processorService) PatternAnalysis(ctx context.Context, startDate, endDate time.Time) (*model.PatternReport, error) {
+	results, err := p.resultRepo.BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPatternReport(draws), nil
+}
+
+func (p *processorService) ShapeAnalysis(ctx context.Context, startDate, endDate time.Time) (*model.ShapeReport, error) {
+	results, err := p.resultRepo.BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildShapeReport(draws, day(endDate)), nil
+}
+
+func (p *processorService) Seasonality(ctx context.Context, startDate, endDate time.Time) (*model.SeasonalityReport, error) {
+	results, err := p.resultRepo.BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildSeasonalityReport(draws)
+	report.DateRange = model.DateRange{StartDate: startDate, EndDate: endDate}
+	return report, nil
+}
+
+func (p *processorService) Dependencies(ctx context.Context, startDate, endDate time.Time, params model.DependencyParams) (*model.DependencyReport, error) {
+	results, err := p.resultRepo.BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	draws, err := newDraws(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Permutations <= 0 {
+		params.Permutations = defaultPermutations
+	}
+	if params.Permutations > maxPermutations {
+		params.Permutations = maxPermutations
+	}
+	if params.Seed == 0 {
+		params.Seed = defaultSeed
+	}
+
+	report, err := buildDependencyReport(ctx, draws, params)
+	if err != nil {
+		return nil, err
+	}
+	report.DateRange = model.DateRange{StartDate: startDate, EndDate: endDate}
+	return report, nil
+}
+
+func (p *processorService) Correlation(ctx context.Context, lotteryA, lotteryB int, startDate, endDate time.Time) (*model.CorrelationReport, error) {
+	if lotteryA == lotteryB {
+		return nil, fmt.Errorf("correlation needs two different lotteries, got %d twice", lotteryA)
+	}
+
+	drawsA, err := p.lotteryDraws(ctx, lotteryA, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	drawsB, err := p.lotteryDraws(ctx, lotteryB, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildCorrelationReport(alignDraws(drawsA, drawsB))
+	report.LotteryA = lotteryA
+	report.LotteryB = lotteryB
+	report.DateRange = model.DateRange{StartDate: startDate, EndDate: endDate}
+	return report, nil
+}
+
+// lotteryDraws carga los sorteos de cualquier lotería guardada, ordenados por fecha.
+func (p *processorService) lotteryDraws(ctx context.Context, lottery int, startDate, endDate time.Time) ([]draw, error) {
+	results, err := p.resultRepo.WithLottery(lottery).BetweenDates(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results of lottery %d: %w", lottery, err)
+	}
+
+	return newDraws(results)
+}
+
 // Funciones auxiliares
 
-func (p *processorService) calculateProbabilityResult(number int, frecuencies *model.FrequencyData) float64 {
-	numberStr := fmt.Sprintf("%04d", number)
-	var prob float64
+// normalizeParams completa los valores por defecto y descarta los que no aplican a la estrategia,
+// para que dos peticiones equivalentes tengan los mismos parámetros.
+func normalizeParams(params model.AnalysisParams) model.AnalysisParams {
+	if params.Strategy == "" {
+		params.Strategy = StrategyFrequency
+	}
+	if params.Mode == "" {
+		params.Mode = ModeCold
+	}
+	if params.TopNumbers <= 0 {
+		params.TopNumbers = defaultTopNumbers
+	}
+	if params.TopNumbers > maxTopNumbers {
+		params.TopNumbers = maxTopNumbers
+	}
+
+	if params.Strategy == StrategyEnsemble {
+		if params.Normalization == "" {
+			params.Normalization = NormalizationRank
+		}
+		if params.FitDraws <= 0 {
+			params.FitDraws = defaultFitDraws
+		}
+		if params.FitDraws > maxFitDraws {
+			params.FitDraws = maxFitDraws
+		}
+	} else {
+		params.Normalization = ""
+		params.FitDraws = 0
+	}
+
+	params.Patterns = canonicalPatterns(params.Patterns)
+	params.ExcludePatterns = canonicalPatterns(params.ExcludePatterns)
 
-	// Convertir a enteros para indexación
-	digits := make([]int, 4)
-	for i, char := range numberStr {
-		digits[i], _ = strconv.Atoi(string(char))
+	if params.Shape == "" {
+		params.ShapeMode = ""
+	} else if params.ShapeMode == "" {
+		params.ShapeMode = ShapeModeFilter
 	}
 
+	if params.Simulations <= 0 {
+		params.Simulations = defaultSimulations
+	}
+	if params.Simulations > maxSimulations {
+		params.Simulations = maxSimulations
+	}
+	if params.Seed == 0 {
+		params.Seed = defaultSeed
+	}
+	if params.BaselineDraws <= 0 {
+		params.BaselineDraws = defaultBaselineDraws
+	}
+
+	if params.Bootstrap < 0 {
+		params.Bootstrap = 0
+	}
+	if params.Bootstrap > maxBootstrap {
+		params.Bootstrap = maxBootstrap
+	}
+
+	if params.AsOf == "" {
+		params.AsOf = today()
+	}
+	if params.Cutoff == "" {
+		params.Cutoff = params.AsOf
+	}
+
+	return params
+}
+
+// withMode devuelve una copia del análisis con el ranking del modo pedido como principal; el análisis
+// recibido puede estar en memoria compartido con otras llamadas y no se modifica.
+func withMode(analysis *model.Analysis, mode string) *model.Analysis {
+	if len(analysis.Rankings) == 0 || analysis.Mode == mode {
+		return analysis
+	}
+
+	ranking := rankingFor(analysis.Rankings, mode)
+	selected := *analysis
+	selected.Mode = ranking.Mode
+	selected.Params.Mode = ranking.Mode
+	selected.BestNumbers = ranking.Numbers
+	selected.BestScores = ranking.Scores
+	selected.BestSigns = ranking.Signs
+	selected.BestSignScores = ranking.SignScores
+	selected.BestCombinations = ranking.Combinations
+	selected.Baseline = ranking.Baseline
+	selected.Stability = ranking.Stability
+	if ranking.Ensemble != nil {
+		ensemble := *ranking.Ensemble
+		selected.Ensemble = &ensemble
+	}
+	return &selected
+}
+
+// rankingFor devuelve el ranking del modo pedido o el primero si no está; los análisis calculados
+// tienen todos los modos. Para los guardados, donde puede faltar, se usa findRanking.
+func rankingFor(rankings []model.Ranking, mode string) model.Ranking {
+	if ranking, ok := findRanking(rankings, mode); ok {
+		return ranking
+	}
+	return rankings[0]
+}
+
+// findRanking busca el ranking del modo.
+func findRanking(rankings []model.Ranking, mode string) (model.Ranking, bool) {
+	for _, ranking := range rankings {
+		if ranking.Mode == mode {
+			return ranking, true
+		}
+	}
+	return model.Ranking{}, false
+}
+
+// buildRanking selecciona los mejores números, signos y combinaciones para un modo.
+func (p *processorService) buildRanking(mode string, scores []float64, filter *numberFilter, frecuencies *model.FrequencyData, size int) model.Ranking {
+	numbers, _ := p.rankNumbers(modeKeys(scores, mode), filter, size)
+
+	numberScores := make([]float64, len(numbers))
+	for i, number := range numbers {
+		numberScores[i] = scores[number]
+	}
+
+	signs, signScores := p.rankSigns(frecuencies, mode)
+
+	return model.Ranking{
+		Mode:         mode,
+		Numbers:      numbers,
+		Scores:       numberScores,
+		Signs:        signs,
+		SignScores:   signScores,
+		Combinations: p.bestCombinations(numbers, numberScores, frecuencies, mode),
+	}
+}
+
+func calculateProbabilityResult(number int, frecuencies *model.FrequencyData) float64 {
+	var prob float64
+
+	// Dígitos por aritmética, sin pasar por strings
+	digits := utils.Digits(number)
+
 	// Probabilidad por dígito individual
 	prob += frecuencies.DigitFreq.Position1[digits[0]]
 	prob += frecuencies.DigitFreq.Position2[digits[1]]
@@ -208,27 +854,109 @@ func (p *processorService) calculateProbabilityResult(number int, frecuencies *m
 	return prob
 }
 
-func (p *processorService) sortBestNumbers(numbers []int, scores []float64) {
-	// Crear slice de índices para ordenamiento
-	indices := make([]int, len(scores))
+// rankSigns ordena los signos con el mismo criterio que los números según el modo.
+func (p *processorService) rankSigns(frecuencies *model.FrequencyData, mode string) ([]string, []float64) {
+	signs := make([]string, len(model.Signs))
+	scores := make([]float64, len(model.Signs))
+	copy(signs, model.Signs)
+	copy(scores, frecuencies.SignFreq.Signs)
+
+	sort.Stable(signRanking{signs: signs, scores: scores, keys: modeKeys(scores, mode)})
+	return signs, scores
+}
+
+// calculateCombinationScore suma al score del número el del signo y la frecuencia conjunta
+// del signo con cada uno de sus dígitos.
+func (p *processorService) calculateCombinationScore(number int, numberScore float64, signIdx int, frecuencies *model.FrequencyData) float64 {
+	digits := utils.Digits(number)
+
+	score := numberScore + frecuencies.SignFreq.Signs[signIdx]
+	score += frecuencies.SignFreq.Position1[signIdx*10+digits[0]]
+	score += frecuencies.SignFreq.Position2[signIdx*10+digits[1]]
+	score += frecuencies.SignFreq.Position3[signIdx*10+digits[2]]
+	score += frecuencies.SignFreq.Position4[signIdx*10+digits[3]]
+	return score
+}
+
+// bestCombinations cruza los mejores números con todos los signos y se queda con las mejores
+// combinaciones, tantas como números haya.
+func (p *processorService) bestCombinations(numbers []int, scores []float64, frecuencies *model.FrequencyData, mode string) []model.NumberSign {
+	combinations := make([]model.NumberSign, 0, len(numbers)*len(model.Signs))
+	for i, number := range numbers {
+		for signIdx, sign := range model.Signs {
+			combinations = append(combinations, model.NumberSign{
+				Number: number,
+				Sign:   sign,
+				Score:  p.calculateCombinationScore(number, scores[i], signIdx, frecuencies),
+			})
+		}
+	}
+
+	values := make([]float64, len(combinations))
+	for i, c := range combinations {
+		values[i] = c.Score
+	}
+	keys := modeKeys(values, mode)
+
+	indices := make([]int, len(combinations))
 	for i := range indices {
 		indices[i] = i
 	}
-
-	// Ordenar por scores
-	sort.Slice(indices, func(i, j int) bool {
-		return scores[indices[i]] < scores[indices[j]]
+	sort.SliceStable(indices, func(i, j int) bool {
+		return keys[indices[i]] < keys[indices[j]]
 	})
 
-	// Reordenar arrays
-	tempNumbers := make([]int, len(numbers))
-	tempScores := make([]float64, len(scores))
+	best := make([]model.NumberSign, len(numbers))
+	for i := range best {
+		best[i] = combinations[indices[i]]
+	}
+	return best
+}
+
+type signRanking struct {
+	signs  []string
+	scores []float64
+	keys   []float64
+}
+
+func (s signRanking) Len() int           { return len(s.signs) }
+func (s signRanking) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s signRanking) Swap(i, j int) {
+	s.signs[i], s.signs[j] = s.signs[j], s.signs[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// rankNumbers aplica el filtro y, si hay perfil de forma preferido, pone primero los números que lo
+// cumplen y completa con el resto.
+func (p *processorService) rankNumbers(scores []float64, filter *numberFilter, size int) ([]int, []float64) {
+	if filter.preferred == nil {
+		return p.selectBest(scores, filter.accepts, size)
+	}
+
+	numbers, best := p.selectBest(scores, func(number int) bool {
+		return filter.accepts(number) && filter.preferred(number)
+	}, size)
+
+	if len(numbers) < size {
+		restNumbers, restScores := p.selectBest(scores, func(number int) bool {
+			return filter.accepts(number) && !filter.preferred(number)
+		}, size-len(numbers))
+		numbers = append(numbers, restNumbers...)
+		best = append(best, restScores...)
+	}
+
+	return numbers, best
+}
+
+// selectBest se queda con los size números aceptados de menor score.
+func (p *processorService) selectBest(scores []float64, accept func(number int) bool, size int) ([]int, []float64) {
+	bestNumbers := topNumbers(scores, accept, size)
 
-	for i, idx := range indices {
-		tempNumbers[i] = numbers[idx]
-		tempScores[i] = scores[idx]
+	bestScores := make([]float64, len(bestNumbers))
+	for i, number := range bestNumbers {
+		bestScores[i] = scores[number]
 	}
 
-	copy(numbers, tempNumbers)
-	copy(scores, tempScores)
+	return bestNumbers, bestScores
 }
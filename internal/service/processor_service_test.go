@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func newTestProcessor(repo *fakeResultRepo) *processorService {
+	return &processorService{resultRepo: repo, cache: newAnalysisCache(), flight: newAnalysisFlight()}
+}
+
+func TestProcessAnalysisModesConcurrently(t *testing.T) {
+	p := newTestProcessor(newFakeResultRepo(1, testHistory(120, 1)...))
+	params := model.AnalysisParams{
+		Strategy:      StrategyEnsemble,
+		FitDraws:      5,
+		Simulations:   50,
+		BaselineDraws: 10,
+		AsOf:          "2020-04-30",
+		Cutoff:        "2020-04-29",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		for _, mode := range SelectionModes {
+			wg.Add(1)
+			go func(mode string) {
+				defer wg.Done()
+				params := params
+				params.Mode = mode
+				analysis, err := p.ProcessAnalysis(context.Background(), params)
+				if err != nil {
+					t.Errorf("%s: %v", mode, err)
+					return
+				}
+				if analysis.Mode != mode || analysis.Params.Mode != mode {
+					t.Errorf("%s: got mode %s, params mode %s", mode, analysis.Mode, analysis.Params.Mode)
+				}
+				if analysis.Ensemble == nil || analysis.Ensemble.Mode != mode {
+					t.Errorf("%s: got ensemble report %+v", mode, analysis.Ensemble)
+				}
+			}(mode)
+		}
+	}
+	wg.Wait()
+
+	// Lo que quedó en memoria no cambió con los otros modos
+	cached, err := p.ProcessAnalysis(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Mode != ModeCold || cached.Ensemble.Mode != ModeCold {
+		t.Errorf("cached analysis has mode %s and ensemble mode %s, want %s", cached.Mode, cached.Ensemble.Mode, ModeCold)
+	}
+}
+
+func TestSpacesAreShared(t *testing.T) {
+	p := newTestProcessor(newFakeResultRepo(1, testHistory(60, 2)...))
+	params := normalizeParams(model.AnalysisParams{AsOf: "2020-02-29", Cutoff: "2020-02-28"})
+
+	first, err := p.currentSpace(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Los filtros y el modo no cambian los scores
+	params.Mode = ModeHot
+	params.Patterns = PatternDouble
+	second, err := p.currentSpace(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("the scored space was calculated twice for the same data")
+	}
+
+	p.cache.invalidate()
+	third, err := p.currentSpace(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first {
+		t.Error("the scored space survived the cache invalidation")
+	}
+}
+
+func TestNormalizeParamsLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		params model.AnalysisParams
+		check  func(params model.AnalysisParams) bool
+	}{
+		{"fit draws default", model.AnalysisParams{Strategy: StrategyEnsemble},
+			func(p model.AnalysisParams) bool { return p.FitDraws == defaultFitDraws }},
+		{"fit draws capped", model.AnalysisParams{Strategy: StrategyEnsemble, FitDraws: 1000000},
+			func(p model.AnalysisParams) bool { return p.FitDraws == maxFitDraws }},
+		{"fit draws only for the ensemble", model.AnalysisParams{Strategy: StrategyPair, FitDraws: 50},
+			func(p model.AnalysisParams) bool { return p.FitDraws == 0 }},
+		{"top default", model.AnalysisParams{},
+			func(p model.AnalysisParams) bool { return p.TopNumbers == defaultTopNumbers }},
+		{"top capped", model.AnalysisParams{TopNumbers: 1000000},
+			func(p model.AnalysisParams) bool { return p.TopNumbers == maxTopNumbers }},
+		{"bootstrap off by default", model.AnalysisParams{},
+			func(p model.AnalysisParams) bool { return p.Bootstrap == 0 }},
+		{"bootstrap capped", model.AnalysisParams{Bootstrap: 1000000},
+			func(p model.AnalysisParams) bool { return p.Bootstrap == maxBootstrap }},
+		{"simulations capped", model.AnalysisParams{Simulations: 1000000},
+			func(p model.AnalysisParams) bool { return p.Simulations == maxSimulations }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeParams(tt.params); !tt.check(got) {
+				t.Errorf("normalizeParams = %+v", got)
+			}
+		})
+	}
+}
+
+func TestAnalysisIsReproducible(t *testing.T) {
+	ctx := context.Background()
+	history := testHistory(90, 4)
+	params := model.AnalysisParams{
+		Strategy:      StrategyEnsemble,
+		FitDraws:      5,
+		Simulations:   50,
+		BaselineDraws: 10,
+		AsOf:          "2020-03-15",
+	}
+
+	run := func(repo *fakeResultRepo) (*model.Analysis, *model.FrequencyData) {
+		p := newTestProcessor(repo)
+		analysis, err := p.ProcessAnalysis(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		space, err := p.currentSpace(ctx, normalizeParams(params))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return analysis, space.frequencies
+	}
+
+	first, firstFrequencies := run(newFakeResultRepo(1, history...))
+
+	// Otro proceso, con un sorteo posterior a as_of ya guardado, calcula todo de nuevo
+	later := &model.Result{ID: len(history) + 1, Date: testDate("2020-04-15"), First: 1, Second: 2, Third: 3, Fourth: 4, Sign: model.Signs[0]}
+	second, secondFrequencies := run(newFakeResultRepo(1, append(history[:len(history):len(history)], later)...))
+
+	if !reflect.DeepEqual(first.Rankings, second.Rankings) {
+		t.Error("the rankings changed after a draw later than as_of")
+	}
+	if !reflect.DeepEqual(first.Ensemble, second.Ensemble) || !reflect.DeepEqual(first.Baseline, second.Baseline) {
+		t.Error("the ensemble weights or the baseline changed after a draw later than as_of")
+	}
+	if !reflect.DeepEqual(firstFrequencies, secondFrequencies) {
+		t.Error("the frequencies changed after a draw later than as_of")
+	}
+	if !first.Timestamp.Equal(second.Timestamp) || first.TotalProcessed != second.TotalProcessed {
+		t.Errorf("runs at %v/%d and %v/%d", first.Timestamp, first.TotalProcessed, second.Timestamp, second.TotalProcessed)
+	}
+}
+
+func TestBestNumbersEntries(t *testing.T) {
+	p := newTestProcessor(newFakeResultRepo(1, testHistory(60, 12)...))
+	params := model.AnalysisParams{TopNumbers: 20, Simulations: 20, BaselineDraws: 5, AsOf: "2020-02-20"}
+
+	tests := []struct {
+		name      string
+		bootstrap int
+	}{
+		{"without bootstrap", 0},
+		{"with bootstrap", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := params
+			params.Bootstrap = tt.bootstrap
+			best, err := p.BestNumbers(context.Background(), params, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(best.Entries) != 5 {
+				t.Fatalf("%d entries, want 5", len(best.Entries))
+			}
+			for i, entry := range best.Entries {
+				if entry.Rank != i+1 || entry.Number != best.Numbers[i] || entry.Score != best.Scores[i] {
+					t.Errorf("entry %d is %+v, want number %d", i, entry, best.Numbers[i])
+				}
+				if (entry.Stability != nil) != (tt.bootstrap > 0) {
+					t.Errorf("entry %d has stability %+v with bootstrap %d", i, entry.Stability, tt.bootstrap)
+				}
+				if entry.Stability != nil && entry.Stability.Number != entry.Number {
+					t.Errorf("entry %d: stability of %d next to %d", i, entry.Stability.Number, entry.Number)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"lottery-analyzer/internal/model"
+)
+
+// Pasos de un análisis que se informan en el progreso.
+const (
+	StepScraping    = "scraping"
+	StepFrequencies = "frequencies"
+	StepRanking     = "ranking"
+	StepBootstrap   = "bootstrap"
+	StepSaving      = "saving"
+)
+
+// Progress cuenta el avance de un análisis. Viaja en el contexto para que el scrapping y el cálculo
+// de frecuencias lo actualicen sin cambiar sus firmas; sin Progress en el contexto no se cuenta nada.
+type Progress struct {
+	mu    sync.Mutex
+	state model.JobProgress
+}
+
+type progressKey struct{}
+
+// WithProgress devuelve un contexto que informa el avance en progress.
+func WithProgress(ctx context.Context, progress *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+func progressFrom(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressKey{}).(*Progress)
+	return progress
+}
+
+// Snapshot devuelve una copia del avance.
+func (p *Progress) Snapshot() model.JobProgress {
+	if p == nil {
+		return model.JobProgress{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+func (p *Progress) update(change func(state *model.JobProgress)) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	change(&p.state)
+}
+
+func reportStep(ctx context.Context, step string) {
+	progressFrom(ctx).update(func(state *model.JobProgress) { state.Step = step })
+}
+
+func reportScrapedDate(ctx context.Context) {
+	progressFrom(ctx).update(func(state *model.JobProgress) { state.ScrapedDates++ })
+}
+
+func reportWindows(ctx context.Context, done, total int) {
+	progressFrom(ctx).update(func(state *model.JobProgress) {
+		state.Windows, state.TotalWindows = done, total
+	})
+}
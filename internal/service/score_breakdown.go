@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// scoreComponent describe uno de los 15 sumandos de calculateProbabilityResult.
+type scoreComponent struct {
+	name      string
+	kind      string
+	positions []int
+	weight    float64 // factor de la posición por el weighting del tipo
+	values    func(f *model.FrequencyData) []float64
+}
+
+// scoreComponents están en el mismo orden en que calculateProbabilityResult los suma.
+var scoreComponents = []scoreComponent{
+	{"first", "single", []int{0}, digitFactors[0], func(f *model.FrequencyData) []float64 { return f.DigitFreq.Position1 }},
+	{"second", "single", []int{1}, digitFactors[1], func(f *model.FrequencyData) []float64 { return f.DigitFreq.Position2 }},
+	{"third", "single", []int{2}, digitFactors[2], func(f *model.FrequencyData) []float64 { return f.DigitFreq.Position3 }},
+	{"fourth", "single", []int{3}, digitFactors[3], func(f *model.FrequencyData) []float64 { return f.DigitFreq.Position4 }},
+	{"first_second", "pair", []int{0, 1}, twoDigitFactors[0] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.FirstSecond }},
+	{"second_third", "pair", []int{1, 2}, twoDigitFactors[3] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.SecondThird }},
+	{"third_fourth", "pair", []int{2, 3}, twoDigitFactors[5] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.ThirdFourth }},
+	{"first_third", "pair", []int{0, 2}, twoDigitFactors[1] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.FirstThird }},
+	{"first_fourth", "pair", []int{0, 3}, twoDigitFactors[2] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.FirstFourth }},
+	{"second_fourth", "pair", []int{1, 3}, twoDigitFactors[4] * 10, func(f *model.FrequencyData) []float64 { return f.TwoDigitFreq.SecondFourth }},
+	{"first_second_third", "triple", []int{0, 1, 2}, threeDigitFactors[0] * 100, func(f *model.FrequencyData) []float64 { return f.ThreeDigitFreq.FirstSecondThird }},
+	{"first_second_fourth", "triple", []int{0, 1, 3}, threeDigitFactors[1] * 100, func(f *model.FrequencyData) []float64 { return f.ThreeDigitFreq.FirstSecondFourth }},
+	{"first_third_fourth", "triple", []int{0, 2, 3}, threeDigitFactors[2] * 100, func(f *model.FrequencyData) []float64 { return f.ThreeDigitFreq.FirstThirdFourth }},
+	{"second_third_fourth", "triple", []int{1, 2, 3}, threeDigitFactors[3] * 100, func(f *model.FrequencyData) []float64 { return f.ThreeDigitFreq.SecondThirdFourth }},
+	{"complete", "full", []int{0, 1, 2, 3}, 1000, func(f *model.FrequencyData) []float64 { return f.FourDigitFreq.Complete }},
+}
+
+// key devuelve los dígitos del número en las posiciones del componente y su índice en la tabla.
+func (c scoreComponent) key(digits [4]int) (string, int) {
+	key, index := "", 0
+	for _, pos := range c.positions {
+		key += fmt.Sprint(digits[pos])
+		index = index*10 + digits[pos]
+	}
+	return key, index
+}
+
+// matches indica si el sorteo coincide con el número en las posiciones del componente.
+func (c scoreComponent) matches(digits [4]int, r *model.Result) bool {
+	drawn := [4]int{r.First, r.Second, r.Third, r.Fourth}
+	for _, pos := range c.positions {
+		if drawn[pos] != digits[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreBreakdown explica el score de un número: el valor de cada componente y lo que aporta cada
+// ventana (apariciones / sorteos de la ventana * ponderación).
+func scoreBreakdown(number int, draws []draw, from time.Time, frequencies *model.FrequencyData) []model.ScoreComponent {
+	digits := utils.Digits(number)
+	windows := fibonacciWindows(from)
+
+	components := make([]model.ScoreComponent, 0, len(scoreComponents))
+	for _, c := range scoreComponents {
+		key, index := c.key(digits)
+		component := model.ScoreComponent{
+			Name:  c.name,
+			Kind:  c.kind,
+			Key:   key,
+			Score: c.values(frequencies)[index],
+		}
+
+		count, total, next := 0, 0, len(draws)
+		for _, cutoff := range windows {
+			for next > 0 && draws[next-1].date.After(cutoff) {
+				next--
+				total++
+				if c.matches(digits, draws[next].result) {
+					count++
+				}
+			}
+
+			window := model.WindowContribution{Since: cutoff, Count: count, Total: total}
+			if total > 0 {
+				window.Contribution = (float64(count) / float64(total)) * c.weight
+			}
+			component.Windows = append(component.Windows, window)
+		}
+
+		// Apariciones en la ventana más amplia
+		component.Count = count
+		components = append(components, component)
+	}
+
+	return components
+}
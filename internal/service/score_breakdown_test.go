@@ -0,0 +1,65 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+// randomFrequencies llena todas las tablas con valores al azar reproducibles.
+func randomFrequencies(seed int64) *model.FrequencyData {
+	rng := rand.New(rand.NewSource(seed))
+	f := newFrequencyData()
+	for _, table := range frequencyTables(f) {
+		for i := range table.values {
+			table.values[i] = rng.Float64()
+		}
+	}
+	return f
+}
+
+func TestScoreComponentsAddUpToTheScore(t *testing.T) {
+	f := randomFrequencies(1)
+
+	for _, number := range []int{0, 7, 427, 1234, 9090, 9999} {
+		components := scoreBreakdown(number, nil, testDraws("2024-01-01")[0].date, f)
+		if len(components) != 15 {
+			t.Fatalf("%04d: %d components, want 15", number, len(components))
+		}
+
+		sum := 0.0
+		for _, c := range components {
+			sum += c.Score
+		}
+		if want := calculateProbabilityResult(number, f); math.Abs(sum-want) > 1e-9 {
+			t.Errorf("%04d: components add up to %v, score is %v", number, sum, want)
+		}
+	}
+}
+
+func TestScoreComponentKey(t *testing.T) {
+	digits := [4]int{1, 2, 3, 4}
+	tests := []struct {
+		name  string
+		key   string
+		index int
+	}{
+		{"first", "1", 1},
+		{"second_fourth", "24", 24},
+		{"first_third_fourth", "134", 134},
+		{"complete", "1234", 1234},
+	}
+
+	for _, tt := range tests {
+		for _, c := range scoreComponents {
+			if c.name != tt.name {
+				continue
+			}
+			if key, index := c.key(digits); key != tt.key || index != tt.index {
+				t.Errorf("%s: key %q index %d, want %q %d", tt.name, key, index, tt.key, tt.index)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"container/heap"
+	"runtime"
+	"sort"
+	"sync"
+
+	"lottery-analyzer/internal/model"
+)
+
+const (
+	defaultTopNumbers = 100
+	// maxTopNumbers es el espacio completo: un ranking más largo no tiene más números que ordenar
+	maxTopNumbers = 10000
+)
+
+// scoreAll puntúa los 10.000 números repartiéndolos en bloques entre tantas goroutines como CPUs.
+// Cada número se calcula de forma independiente, así que el resultado no depende del reparto.
+func scoreAll(score func(number int, frecuencies *model.FrequencyData) float64, frecuencies *model.FrequencyData) []float64 {
+	scores := make([]float64, 10000)
+
+	workers := runtime.GOMAXPROCS(0)
+	chunk := (len(scores) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(scores); start += chunk {
+		end := start + chunk
+		if end > len(scores) {
+			end = len(scores)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for number := start; number < end; number++ {
+				scores[number] = score(number, frecuencies)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	return scores
+}
+
+// candidate es un número con su clave de orden; menor clave es mejor y a igual clave gana el número menor.
+type candidate struct {
+	number int
+	key    float64
+}
+
+func (c candidate) better(other candidate) bool {
+	if c.key != other.key {
+		return c.key < other.key
+	}
+	return c.number < other.number
+}
+
+// worstFirst es un heap cuya raíz es el peor de los candidatos guardados.
+type worstFirst []candidate
+
+func (h worstFirst) Len() int            { return len(h) }
+func (h worstFirst) Less(i, j int) bool  { return h[j].better(h[i]) }
+func (h worstFirst) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *worstFirst) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *worstFirst) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// topNumbers devuelve, ordenados, los n números aceptados con menor clave usando un heap acotado a n.
+func topNumbers(keys []float64, accept func(number int) bool, n int) []int {
+	if n <= 0 {
+		return []int{}
+	}
+
+	h := make(worstFirst, 0, n)
+	for number, key := range keys {
+		if !accept(number) {
+			continue
+		}
+
+		c := candidate{number: number, key: key}
+		if len(h) < n {
+			heap.Push(&h, c)
+		} else if c.better(h[0]) {
+			h[0] = c
+			heap.Fix(&h, 0)
+		}
+	}
+
+	sort.Slice(h, func(i, j int) bool { return h[i].better(h[j]) })
+
+	numbers := make([]int, len(h))
+	for i, c := range h {
+		numbers[i] = c.number
+	}
+	return numbers
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+// legacyBestNumbers es la selección original (fmt.Sprintf + strconv.Atoi por número y reordenar los
+// mejores en cada mejora), conservada como referencia del motor de puntuación.
+func legacyBestNumbers(frecuencies *model.FrequencyData, n int) ([]int, []float64) {
+	bestNumbers := make([]int, n)
+	bestScores := make([]float64, n)
+
+	// Inicializar con valores altos
+	for i := range bestScores {
+		bestScores[i] = 10000.0
+		bestNumbers[i] = 1000 - i
+	}
+
+	for number := 0; number < 10000; number++ {
+		score := legacyProbabilityResult(number, frecuencies)
+
+		// Si el score es mejor que el peor de los mejores
+		if bestScores[n-1] > score {
+			bestScores[n-1] = score
+			bestNumbers[n-1] = number
+
+			// Ordenar y reorganizar
+			legacySortBestNumbers(bestNumbers, bestScores)
+		}
+	}
+
+	return bestNumbers, bestScores
+}
+
+func legacyProbabilityResult(number int, frecuencies *model.FrequencyData) float64 {
+	numberStr := fmt.Sprintf("%04d", number)
+
+	// Convertir a enteros para indexación
+	digits := make([]int, 4)
+	for i, char := range numberStr {
+		digits[i], _ = strconv.Atoi(string(char))
+	}
+
+	var prob float64
+	prob += frecuencies.DigitFreq.Position1[digits[0]]
+	prob += frecuencies.DigitFreq.Position2[digits[1]]
+	prob += frecuencies.DigitFreq.Position3[digits[2]]
+	prob += frecuencies.DigitFreq.Position4[digits[3]]
+
+	prob += frecuencies.TwoDigitFreq.FirstSecond[digits[0]*10+digits[1]]
+	prob += frecuencies.TwoDigitFreq.SecondThird[digits[1]*10+digits[2]]
+	prob += frecuencies.TwoDigitFreq.ThirdFourth[digits[2]*10+digits[3]]
+	prob += frecuencies.TwoDigitFreq.FirstThird[digits[0]*10+digits[2]]
+	prob += frecuencies.TwoDigitFreq.FirstFourth[digits[0]*10+digits[3]]
+	prob += frecuencies.TwoDigitFreq.SecondFourth[digits[1]*10+digits[3]]
+
+	prob += frecuencies.ThreeDigitFreq.FirstSecondThird[digits[0]*100+digits[1]*10+digits[2]]
+	prob += frecuencies.ThreeDigitFreq.FirstSecondFourth[digits[0]*100+digits[1]*10+digits[3]]
+	prob += frecuencies.ThreeDigitFreq.FirstThirdFourth[digits[0]*100+digits[2]*10+digits[3]]
+	prob += frecuencies.ThreeDigitFreq.SecondThirdFourth[digits[1]*100+digits[2]*10+digits[3]]
+
+	prob += frecuencies.FourDigitFreq.Complete[number]
+	return prob
+}
+
+func legacySortBestNumbers(numbers []int, scores []float64) {
+	// Crear slice de índices para ordenamiento
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	// Ordenar por scores
+	sort.Slice(indices, func(i, j int) bool {
+		return scores[indices[i]] < scores[indices[j]]
+	})
+
+	// Reordenar arrays
+	tempNumbers := make([]int, len(numbers))
+	tempScores := make([]float64, len(scores))
+
+	for i, idx := range indices {
+		tempNumbers[i] = numbers[idx]
+		tempScores[i] = scores[idx]
+	}
+
+	copy(numbers, tempNumbers)
+	copy(scores, tempScores)
+}
+
+func acceptAll(int) bool { return true }
+
+func TestTopNumbersMatchesLegacy(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		f := randomFrequencies(seed)
+		for _, n := range []int{1, 10, 100, 500} {
+			legacyNumbers, legacyScores := legacyBestNumbers(f, n)
+			scores := scoreAll(calculateProbabilityResult, f)
+			numbers := topNumbers(scores, acceptAll, n)
+
+			if len(numbers) != len(legacyNumbers) {
+				t.Fatalf("seed %d top %d: %d numbers, legacy has %d", seed, n, len(numbers), len(legacyNumbers))
+			}
+			for i := range numbers {
+				if numbers[i] != legacyNumbers[i] || scores[numbers[i]] != legacyScores[i] {
+					t.Fatalf("seed %d top %d: position %d is %04d (%v), legacy %04d (%v)",
+						seed, n, i, numbers[i], scores[numbers[i]], legacyNumbers[i], legacyScores[i])
+				}
+			}
+		}
+	}
+}
+
+func TestTopNumbers(t *testing.T) {
+	keys := []float64{5, 1, 3, 1, 4, 0}
+	even := func(number int) bool { return number%2 == 0 }
+
+	tests := []struct {
+		name   string
+		accept func(int) bool
+		n      int
+		want   []int
+	}{
+		{"ties go to the lower number", acceptAll, 3, []int{5, 1, 3}},
+		{"all", acceptAll, 6, []int{5, 1, 3, 2, 4, 0}},
+		{"more than available", acceptAll, 10, []int{5, 1, 3, 2, 4, 0}},
+		{"filtered", even, 2, []int{2, 4}},
+		{"none", acceptAll, 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := topNumbers(keys, tt.accept, tt.n)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("topNumbers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreAllMatchesSequential(t *testing.T) {
+	f := randomFrequencies(4)
+	scores := scoreAll(calculateProbabilityResult, f)
+	for number, score := range scores {
+		if want := legacyProbabilityResult(number, f); score != want {
+			t.Fatalf("%04d: score %v, want %v", number, score, want)
+		}
+	}
+}
+
+func BenchmarkScoringLegacy(b *testing.B) {
+	f := randomFrequencies(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		legacyBestNumbers(f, defaultTopNumbers)
+	}
+}
+
+func BenchmarkScoringEngine(b *testing.B) {
+	f := randomFrequencies(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		topNumbers(scoreAll(calculateProbabilityResult, f), acceptAll, defaultTopNumbers)
+	}
+}
@@ -11,33 +11,38 @@ import (
 
 	"lottery-analyzer/internal/model"
 	"lottery-analyzer/internal/repository"
+	"lottery-analyzer/pkg/utils"
 )
 
 type scrapperService struct {
 	resultRepo repository.ResultRepository
+	ledger     LedgerService
 	client     *http.Client
+	listeners  []func(result *model.Result)
 }
 
-func NewScrapperService(resultRepo repository.ResultRepository) ScrapperService {
+func NewScrapperService(resultRepo repository.ResultRepository, ledger LedgerService) ScrapperService {
 	return &scrapperService{
 		resultRepo: resultRepo,
+		ledger:     ledger,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// OnNewResult registra una función que se llama con cada resultado nuevo guardado.
+func (s *scrapperService) OnNewResult(listener func(result *model.Result)) {
+	s.listeners = append(s.listeners, listener)
+}
+
 func (s *scrapperService) LastScrapedDate(ctx context.Context) (*time.Time, error) {
 	result, err := s.resultRepo.LastResult(ctx)
 	if err != nil || result == nil {
 		return nil, err
 	}
 
-	date, err := time.Parse("02/01/2006", result.Date)
-	if err != nil {
-		return nil, fmt.Errorf("invalid date format: %w", err)
-	}
-
+	date := result.Date
 	return &date, nil
 }
 
@@ -61,18 +66,19 @@ func (s *scrapperService) ScrapingFromLastDate(ctx context.Context) error {
 		default:
 		}
 
-		dateStr := date.Format("02/01/2006")
-		if err := s.scrapeDate(ctx, dateStr); err != nil {
+		if err := s.scrapeDate(ctx, date); err != nil {
 			// Log error but continue with next date
-			fmt.Printf("Failed to scrape date %s: %v\n", dateStr, err)
+			fmt.Printf("Failed to scrape date %s: %v\n", date.Format(utils.DateLayout), err)
 		}
+		reportScrapedDate(ctx)
 	}
 
-	return nil
+	return s.settlePending(ctx)
 }
 
-func (s *scrapperService) scrapeDate(ctx context.Context, dateStr string) error {
-	url := fmt.Sprintf("https://resultadodelaloteria.com/ws/services.asmx/getResultado?sFecha=%s&idLoteria=21&valueCaptcha=kZyAcju1QZE5sNoRHMohIg==&txtValueCaptcha=DMNT", dateStr)
+func (s *scrapperService) scrapeDate(ctx context.Context, date time.Time) error {
+	// La web recibe la fecha como dd/mm/yyyy
+	url := fmt.Sprintf("https://resultadodelaloteria.com/ws/services.asmx/getResultado?sFecha=%s&idLoteria=%d&valueCaptcha=kZyAcju1QZE5sNoRHMohIg==&txtValueCaptcha=DMNT", date.Format(utils.DateLayout), s.resultRepo.Lottery())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -106,8 +112,24 @@ func (s *scrapperService) scrapeDate(ctx context.Context, dateStr string) error
 		return fmt.Errorf("failed to parse data: %w", err)
 	}
 
-	result.Date = dateStr
-	return s.resultRepo.Create(ctx, result)
+	result.Date = day(date)
+	if err := s.resultRepo.Create(ctx, result); err != nil {
+		return err
+	}
+
+	for _, listener := range s.listeners {
+		listener(result)
+	}
+	return nil
+}
+
+// settlePending liquida las apuestas de los sorteos ya guardados, los recién scrapeados y los que
+// hayan quedado pendientes de antes.
+func (s *scrapperService) settlePending(ctx context.Context) error {
+	if _, err := s.ledger.Settle(ctx, s.resultRepo.Lottery()); err != nil {
+		return fmt.Errorf("failed to settle tickets: %w", err)
+	}
+	return nil
 }
 
 func (s *scrapperService) cleanData(responseText string) (*model.Result, error) {
@@ -183,10 +205,10 @@ func (s *scrapperService) ScrapingDateRange(ctx context.Context, startDate, endD
 		default:
 		}
 
-		dateStr := date.Format("02/01/2006")
-		if err := s.scrapeDate(ctx, dateStr); err != nil {
-			return fmt.Errorf("failed to scrape date %s: %w", dateStr, err)
+		if err := s.scrapeDate(ctx, date); err != nil {
+			return fmt.Errorf("failed to scrape date %s: %w", date.Format(utils.DateLayout), err)
 		}
+		reportScrapedDate(ctx)
 	}
-	return nil
+	return s.settlePending(ctx)
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// seasonalCounts son los conteos por posición y de signos de un grupo de sorteos.
+type seasonalCounts struct {
+	draws  int
+	digits [4][10]int
+	signs  [12]int
+}
+
+func (c *seasonalCounts) add(r *model.Result) {
+	c.draws++
+	for position, digit := range resultDigits(r) {
+		c.digits[position][digit]++
+	}
+	if idx := model.SignIndex(r.Sign); idx >= 0 {
+		c.signs[idx]++
+	}
+}
+
+// buildSeasonalityReport agrupa los sorteos por día de la semana y por mes.
+func buildSeasonalityReport(draws []draw) *model.SeasonalityReport {
+	weekdays := make([]seasonalCounts, 7)
+	months := make([]seasonalCounts, 12)
+	overall := &seasonalCounts{}
+
+	for _, dr := range draws {
+		weekdays[dr.date.Weekday()].add(dr.result)
+		months[dr.date.Month()-1].add(dr.result)
+		overall.add(dr.result)
+	}
+
+	weekdayLabels := make([]string, 7)
+	for i := range weekdayLabels {
+		weekdayLabels[i] = time.Weekday(i).String()
+	}
+	monthLabels := make([]string, 12)
+	for i := range monthLabels {
+		monthLabels[i] = time.Month(i + 1).String()
+	}
+
+	return &model.SeasonalityReport{
+		TotalDraws: len(draws),
+		Weekday:    seasonalBreakdown(weekdays, weekdayLabels, overall),
+		Month:      seasonalBreakdown(months, monthLabels, overall),
+	}
+}
+
+func seasonalBreakdown(groups []seasonalCounts, labels []string, overall *seasonalCounts) model.SeasonalBreakdown {
+	var breakdown model.SeasonalBreakdown
+
+	// Independencia entre el grupo y el valor: filas grupos, columnas dígitos o signos
+	for position, name := range digitPositions {
+		table := make([][]int, len(groups))
+		for i := range groups {
+			table[i] = groups[i].digits[position][:]
+		}
+		breakdown.Positions = append(breakdown.Positions, independenceTest(name, table))
+	}
+
+	signTable := make([][]int, len(groups))
+	for i := range groups {
+		signTable[i] = groups[i].signs[:]
+	}
+	breakdown.Sign = independenceTest("sign", signTable)
+
+	// Cada grupo contra la distribución de todos los sorteos
+	for i, group := range groups {
+		if group.draws == 0 {
+			continue
+		}
+
+		seasonal := model.SeasonalGroup{
+			Label:    labels[i],
+			Draws:    group.draws,
+			Signs:    append([]int(nil), group.signs[:]...),
+			SignTest: goodnessOfFitTest("sign", group.signs[:], overall.signs[:]),
+		}
+		for position, name := range digitPositions {
+			seasonal.Digits = append(seasonal.Digits, append([]int(nil), group.digits[position][:]...))
+			seasonal.DigitTests = append(seasonal.DigitTests,
+				goodnessOfFitTest(name, group.digits[position][:], overall.digits[position][:]))
+		}
+		breakdown.Groups = append(breakdown.Groups, seasonal)
+	}
+
+	return breakdown
+}
+
+func independenceTest(position string, table [][]int) model.SeasonalTest {
+	stat, df := utils.ChiSquareIndependence(table)
+	return seasonalTest(position, stat, df)
+}
+
+func goodnessOfFitTest(position string, observed, overall []int) model.SeasonalTest {
+	stat, df := utils.ChiSquareGoodnessOfFit(observed, shares(overall))
+	return seasonalTest(position, stat, df)
+}
+
+func seasonalTest(position string, stat float64, df int) model.SeasonalTest {
+	test := model.SeasonalTest{Position: position, ChiSquare: stat, DegreesOfFreedom: df, PValue: 1}
+	if df > 0 {
+		test.PValue = utils.ChiSquarePValue(stat, df)
+	}
+	return test
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildSeasonalityReport(t *testing.T) {
+	history := testHistory(700, 9)
+	// Los lunes la primera posición siempre es 9
+	for _, result := range history {
+		if result.Date.Weekday() == time.Monday {
+			result.First = 9
+		}
+	}
+	draws, err := newDraws(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := buildSeasonalityReport(draws)
+	if report.TotalDraws != len(history) || len(report.Weekday.Groups) != 7 || len(report.Month.Groups) != 12 {
+		t.Fatalf("%d draws in %d weekdays and %d months", report.TotalDraws, len(report.Weekday.Groups), len(report.Month.Groups))
+	}
+
+	total := 0
+	for _, group := range report.Weekday.Groups {
+		total += group.Draws
+	}
+	if total != len(history) {
+		t.Errorf("weekday groups add up to %d draws, want %d", total, len(history))
+	}
+
+	tests := []struct {
+		name      string
+		pValue    float64
+		dependent bool
+	}{
+		{"weekday x first", report.Weekday.Positions[0].PValue, true},
+		{"weekday x fourth", report.Weekday.Positions[3].PValue, false},
+		{"weekday x sign", report.Weekday.Sign.PValue, false},
+		{"month x first", report.Month.Positions[0].PValue, false},
+		{"monday first digit", report.Weekday.Groups[time.Monday].DigitTests[0].PValue, true},
+		{"tuesday first digit", report.Weekday.Groups[time.Tuesday].DigitTests[0].PValue, false},
+	}
+	for _, tt := range tests {
+		if dependent := tt.pValue < 1e-6; dependent != tt.dependent {
+			t.Errorf("%s: p-value %v, want dependent %v", tt.name, tt.pValue, tt.dependent)
+		}
+	}
+	if got := report.Weekday.Positions[0].DegreesOfFreedom; got != 54 {
+		t.Errorf("weekday x first has %d df, want 54", got)
+	}
+	if got := report.Weekday.Groups[time.Monday]; got.Label != "Monday" || got.Digits[0][9] != got.Draws {
+		t.Errorf("monday group %s has %d nines in %d draws", got.Label, got.Digits[0][9], got.Draws)
+	}
+}
+
+func TestSeasonalityEmptyGroups(t *testing.T) {
+	draws := testDraws("2024-01-01", "2024-01-08", "2024-01-15")
+	report := buildSeasonalityReport(draws)
+
+	// Sólo hay lunes de enero: los demás grupos no aparecen y no hay prueba posible
+	if len(report.Weekday.Groups) != 1 || report.Weekday.Groups[0].Label != "Monday" {
+		t.Errorf("weekday groups %+v", report.Weekday.Groups)
+	}
+	if len(report.Month.Groups) != 1 || report.Month.Groups[0].Label != "January" || report.Month.Groups[0].Draws != 3 {
+		t.Errorf("month groups %+v", report.Month.Groups)
+	}
+	if test := report.Month.Positions[0]; test.DegreesOfFreedom != 0 || test.PValue != 1 {
+		t.Errorf("a single month gave %+v", test)
+	}
+}
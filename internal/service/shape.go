@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// Modos de uso del perfil de forma sobre los mejores números
+const (
+	ShapeModeFilter = "filter" // sólo entran los números con el perfil
+	ShapeModePrefer = "prefer" // los números con el perfil van primero, luego el resto
+)
+
+// numberShape es la forma agregada de un número: suma de dígitos, cuántos pares y cuántos altos (5-9).
+type numberShape struct {
+	sum  int
+	even int
+	high int
+}
+
+func shapeOf(number int) numberShape {
+	var shape numberShape
+	for _, digit := range utils.Digits(number) {
+		shape.sum += digit
+		if digit%2 == 0 {
+			shape.even++
+		}
+		if digit >= 5 {
+			shape.high++
+		}
+	}
+	return shape
+}
+
+// shapeProfile es el perfil preferido, con rangos inclusivos para cada medida.
+type shapeProfile struct {
+	sumMin, sumMax   int
+	evenMin, evenMax int
+	highMin, highMax int
+}
+
+// parseShapeProfile lee perfiles como "sum:10-20,even:2,high:1-3"; las medidas omitidas no restringen.
+func parseShapeProfile(spec string) (*shapeProfile, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	profile := &shapeProfile{sumMax: 36, evenMax: 4, highMax: 4}
+	for _, part := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid shape profile: %s", part)
+		}
+
+		low, high, err := parseRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shape profile %s: %w", name, err)
+		}
+
+		switch name {
+		case "sum":
+			profile.sumMin, profile.sumMax = low, high
+		case "even":
+			profile.evenMin, profile.evenMax = low, high
+		case "high":
+			profile.highMin, profile.highMax = low, high
+		default:
+			return nil, fmt.Errorf("unknown shape measure: %s", name)
+		}
+	}
+
+	return profile, nil
+}
+
+// parseRange lee "a-b" o "a".
+func parseRange(value string) (int, int, error) {
+	lowStr, highStr, isRange := strings.Cut(value, "-")
+	low, err := strconv.Atoi(lowStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return low, low, nil
+	}
+
+	high, err := strconv.Atoi(highStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if high < low {
+		return 0, 0, fmt.Errorf("range %s is empty", value)
+	}
+	return low, high, nil
+}
+
+func (p *shapeProfile) matches(number int) bool {
+	shape := shapeOf(number)
+	return shape.sum >= p.sumMin && shape.sum <= p.sumMax &&
+		shape.even >= p.evenMin && shape.even <= p.evenMax &&
+		shape.high >= p.highMin && shape.high <= p.highMax
+}
+
+// shapeCounts acumula las tres distribuciones de forma.
+type shapeCounts struct {
+	sum  [37]int
+	even [5]int
+	high [5]int
+}
+
+func (c *shapeCounts) add(number int) {
+	shape := shapeOf(number)
+	c.sum[shape.sum]++
+	c.even[shape.even]++
+	c.high[shape.high]++
+}
+
+func shares(counts []int) []float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+
+	result := make([]float64, len(counts))
+	if total == 0 {
+		return result
+	}
+	for i, c := range counts {
+		result[i] = float64(c) / float64(total)
+	}
+	return result
+}
+
+func shapeDistribution(observed []int, theoretical []int) model.ShapeDistribution {
+	expected := shares(theoretical)
+	stat, df := utils.ChiSquareGoodnessOfFit(observed, expected)
+
+	distribution := model.ShapeDistribution{
+		Observed:         append([]int(nil), observed...),
+		ObservedShare:    shares(observed),
+		TheoreticalShare: expected,
+		ChiSquare:        stat,
+		DegreesOfFreedom: df,
+	}
+	if df > 0 {
+		distribution.PValue = utils.ChiSquarePValue(stat, df)
+	}
+	return distribution
+}
+
+// buildShapeReport compara la forma de los sorteos con la distribución exacta sobre los 10.000
+// números, en total y en cada ventana de CalculateFrequencies anclada en from.
+func buildShapeReport(draws []draw, from time.Time) *model.ShapeReport {
+	theoretical := &shapeCounts{}
+	for number := 0; number < 10000; number++ {
+		theoretical.add(number)
+	}
+
+	number := func(dr draw) int {
+		return dr.result.First*1000 + dr.result.Second*100 + dr.result.Third*10 + dr.result.Fourth
+	}
+
+	observed := &shapeCounts{}
+	for _, dr := range draws {
+		observed.add(number(dr))
+	}
+
+	report := &model.ShapeReport{
+		TotalDraws: len(draws),
+		Sum:        shapeDistribution(observed.sum[:], theoretical.sum[:]),
+		Even:       shapeDistribution(observed.even[:], theoretical.even[:]),
+		High:       shapeDistribution(observed.high[:], theoretical.high[:]),
+	}
+
+	// Las ventanas se amplían hacia atrás igual que en frequenciesFromDraws
+	window := &shapeCounts{}
+	total, next := 0, len(draws)
+	for _, cutoff := range fibonacciWindows(from) {
+		for next > 0 && draws[next-1].date.After(cutoff) {
+			next--
+			total++
+			window.add(number(draws[next]))
+		}
+
+		report.Windows = append(report.Windows, model.ShapeWindow{
+			Since:     cutoff,
+			Draws:     total,
+			SumShare:  shares(window.sum[:]),
+			EvenShare: shares(window.even[:]),
+			HighShare: shares(window.high[:]),
+		})
+	}
+
+	return report
+}
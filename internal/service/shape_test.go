@@ -0,0 +1,102 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestParseShapeProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    *shapeProfile
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"all measures", "sum:10-20,even:2,high:1-3", &shapeProfile{10, 20, 2, 2, 1, 3}, false},
+		{"omitted measures do not restrict", " high:4 ", &shapeProfile{0, 36, 0, 4, 4, 4}, false},
+		{"missing colon", "sum10-20", nil, true},
+		{"unknown measure", "odd:2", nil, true},
+		{"not a number", "sum:ten", nil, true},
+		{"bad upper bound", "sum:10-", nil, true},
+		{"empty range", "sum:20-10", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseShapeProfile(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("parseShapeProfile(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShapeProfileMatches(t *testing.T) {
+	profile, err := parseShapeProfile("sum:10-20,even:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		number int
+		want   bool
+	}{
+		{1234, true},  // suma 10, dos pares
+		{5678, false}, // suma 26
+		{1357, false}, // ningún par
+		{8800, false}, // suma 16, cuatro pares
+		{2459, true},  // suma 20, dos pares
+	}
+
+	for _, tt := range tests {
+		if got := profile.matches(tt.number); got != tt.want {
+			t.Errorf("matches(%04d) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestBuildShapeReport(t *testing.T) {
+	draws := testDraws("2024-01-01", "2024-01-09")
+	draws[0].result = &model.Result{Date: draws[0].date, First: 1, Second: 2, Third: 3, Fourth: 4}
+	draws[1].result = &model.Result{Date: draws[1].date, First: 5, Second: 6, Third: 7, Fourth: 8}
+
+	report := buildShapeReport(draws, testDate("2024-01-10"))
+
+	// Distribución exacta sobre los 10.000 números: C(4, k) * 5^4 para pares y altos
+	binomial := []float64{0.0625, 0.25, 0.375, 0.25, 0.0625}
+	for name, distribution := range map[string]model.ShapeDistribution{"even": report.Even, "high": report.High} {
+		for k, share := range binomial {
+			if math.Abs(distribution.TheoreticalShare[k]-share) > 1e-12 {
+				t.Errorf("%s share of %d = %v, want %v", name, k, distribution.TheoreticalShare[k], share)
+			}
+		}
+	}
+	if got := report.Sum.TheoreticalShare; got[0] != 0.0001 || got[18] != 0.067 || got[36] != 0.0001 {
+		t.Errorf("sum shares 0/18/36 = %v/%v/%v, want 0.0001/0.067/0.0001", got[0], got[18], got[36])
+	}
+
+	if report.TotalDraws != 2 || report.Sum.Observed[10] != 1 || report.Sum.Observed[26] != 1 {
+		t.Errorf("observed sums %v over %d draws", report.Sum.Observed, report.TotalDraws)
+	}
+	if report.Even.Observed[2] != 2 || report.High.Observed[0] != 1 || report.High.Observed[4] != 1 {
+		t.Errorf("observed even %v, high %v", report.Even.Observed, report.High.Observed)
+	}
+	if report.High.DegreesOfFreedom != 4 || report.High.PValue <= 0 || report.High.PValue >= 1 {
+		t.Errorf("high test: %d df, p-value %v", report.High.DegreesOfFreedom, report.High.PValue)
+	}
+
+	// La primera ventana (ocho días antes) sólo ve el sorteo del día 9; las amplias ven los dos
+	first, last := report.Windows[0], report.Windows[len(report.Windows)-1]
+	if first.Draws != 1 || first.HighShare[4] != 1 {
+		t.Errorf("first window: %d draws, high shares %v", first.Draws, first.HighShare)
+	}
+	if last.Draws != 2 || last.EvenShare[2] != 1 || last.HighShare[0] != 0.5 {
+		t.Errorf("last window: %d draws, even %v, high %v", last.Draws, last.EvenShare, last.HighShare)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"lottery-analyzer/internal/model"
+)
+
+// repetitionTracker acumula el intervalo (en sorteos) entre apariciones consecutivas de cada valor.
+type repetitionTracker struct {
+	lastSeen []int
+	gapSum   []int
+	gaps     []int
+}
+
+func newRepetitionTracker(size int) *repetitionTracker {
+	t := &repetitionTracker{
+		lastSeen: make([]int, size),
+		gapSum:   make([]int, size),
+		gaps:     make([]int, size),
+	}
+	for i := range t.lastSeen {
+		t.lastSeen[i] = -1
+	}
+	return t
+}
+
+func (t *repetitionTracker) see(value, drawIndex int) {
+	if t.lastSeen[value] >= 0 {
+		t.gapSum[value] += drawIndex - t.lastSeen[value]
+		t.gaps[value]++
+	}
+	t.lastSeen[value] = drawIndex
+}
+
+// means devuelve el intervalo medio de cada valor, 0 si apareció menos de dos veces.
+func (t *repetitionTracker) means() []float64 {
+	means := make([]float64, len(t.gaps))
+	for i, n := range t.gaps {
+		if n > 0 {
+			means[i] = float64(t.gapSum[i]) / float64(n)
+		}
+	}
+	return means
+}
+
+// buildStatistics calcula las frecuencias relativas y las repeticiones de los sorteos recibidos,
+// que deben venir ordenados por fecha.
+func buildStatistics(draws []draw) *model.Statistics {
+	stats := &model.Statistics{TotalResults: len(draws)}
+	if len(draws) == 0 {
+		return stats
+	}
+
+	stats.DateRange = model.DateRange{
+		StartDate: draws[0].date,
+		EndDate:   draws[len(draws)-1].date,
+	}
+
+	counts := &windowCounts{}
+	digitRep := make([]*repetitionTracker, 4)
+	twoDigitRep := make([]*repetitionTracker, 6)
+	threeDigitRep := make([]*repetitionTracker, 4)
+	fourDigitRep := newRepetitionTracker(10000)
+	for i := range digitRep {
+		digitRep[i] = newRepetitionTracker(10)
+		threeDigitRep[i] = newRepetitionTracker(1000)
+	}
+	for i := range twoDigitRep {
+		twoDigitRep[i] = newRepetitionTracker(100)
+	}
+
+	for i, dr := range draws {
+		r := dr.result
+		counts.add(r)
+
+		d := [4]int{r.First, r.Second, r.Third, r.Fourth}
+		for pos := 0; pos < 4; pos++ {
+			digitRep[pos].see(d[pos], i)
+		}
+
+		// Mismo orden que TwoDigitFrequency y ThreeDigitFrequency
+		twoDigitRep[0].see(d[0]*10+d[1], i)
+		twoDigitRep[1].see(d[0]*10+d[2], i)
+		twoDigitRep[2].see(d[0]*10+d[3], i)
+		twoDigitRep[3].see(d[1]*10+d[2], i)
+		twoDigitRep[4].see(d[1]*10+d[3], i)
+		twoDigitRep[5].see(d[2]*10+d[3], i)
+
+		threeDigitRep[0].see(d[0]*100+d[1]*10+d[2], i)
+		threeDigitRep[1].see(d[0]*100+d[1]*10+d[3], i)
+		threeDigitRep[2].see(d[0]*100+d[2]*10+d[3], i)
+		threeDigitRep[3].see(d[1]*100+d[2]*10+d[3], i)
+
+		fourDigitRep.see(d[0]*1000+d[1]*100+d[2]*10+d[3], i)
+	}
+
+	total := float64(len(draws))
+	relative := func(counts []int) []float64 {
+		freq := make([]float64, len(counts))
+		for i, c := range counts {
+			freq[i] = float64(c) / total
+		}
+		return freq
+	}
+
+	stats.DigitFreq = &model.DigitFrequency{
+		Position1: relative(counts.digit[0][:]),
+		Position2: relative(counts.digit[1][:]),
+		Position3: relative(counts.digit[2][:]),
+		Position4: relative(counts.digit[3][:]),
+	}
+	stats.TwoDigitFreq = &model.TwoDigitFrequency{
+		FirstSecond:  relative(counts.twoDigit[0][:]),
+		FirstThird:   relative(counts.twoDigit[1][:]),
+		FirstFourth:  relative(counts.twoDigit[2][:]),
+		SecondThird:  relative(counts.twoDigit[3][:]),
+		SecondFourth: relative(counts.twoDigit[4][:]),
+		ThirdFourth:  relative(counts.twoDigit[5][:]),
+	}
+	stats.ThreeDigitFreq = &model.ThreeDigitFrequency{
+		FirstSecondThird:  relative(counts.threeDigit[0][:]),
+		FirstSecondFourth: relative(counts.threeDigit[1][:]),
+		FirstThirdFourth:  relative(counts.threeDigit[2][:]),
+		SecondThirdFourth: relative(counts.threeDigit[3][:]),
+	}
+	stats.FourDigitFreq = &model.FourDigitFrequency{
+		Complete: relative(counts.fourDigit[:]),
+	}
+
+	stats.Repetition = &model.RepetitionStatistics{FourDigitRep: fourDigitRep.means()}
+	for _, t := range digitRep {
+		stats.Repetition.DigitRep = append(stats.Repetition.DigitRep, t.means())
+	}
+	for _, t := range twoDigitRep {
+		stats.Repetition.TwoDigitRep = append(stats.Repetition.TwoDigitRep, t.means())
+	}
+	for _, t := range threeDigitRep {
+		stats.Repetition.ThreeDigitRep = append(stats.Repetition.ThreeDigitRep, t.means())
+	}
+
+	return stats
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func TestRepetitionTracker(t *testing.T) {
+	tests := []struct {
+		name  string
+		seen  []int // valor visto en cada sorteo
+		value int
+		want  float64
+	}{
+		{"never seen", []int{1, 2, 3}, 0, 0},
+		{"seen once", []int{0, 2, 3}, 0, 0},
+		{"every draw", []int{4, 4, 4, 4}, 4, 1},
+		{"uneven gaps", []int{5, 1, 5, 1, 1, 1, 5}, 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newRepetitionTracker(10)
+			for i, value := range tt.seen {
+				tracker.see(value, i)
+			}
+			if got := tracker.means()[tt.value]; got != tt.want {
+				t.Errorf("mean gap of %d = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStatistics(t *testing.T) {
+	if stats := buildStatistics(nil); stats.TotalResults != 0 || stats.DigitFreq != nil {
+		t.Errorf("statistics of no draws = %+v", stats)
+	}
+
+	draws := testDraws("2024-01-01", "2024-01-02", "2024-01-03", "2024-01-04")
+	for i, number := range []int{1234, 1299, 5234, 1234} {
+		d := [4]int{number / 1000, number / 100 % 10, number / 10 % 10, number % 10}
+		draws[i].result = &model.Result{Date: draws[i].date, First: d[0], Second: d[1], Third: d[2], Fourth: d[3]}
+	}
+	stats := buildStatistics(draws)
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"total", float64(stats.TotalResults), 4},
+		{"first digit 1", stats.DigitFreq.Position1[1], 0.75},
+		{"first digit 5", stats.DigitFreq.Position1[5], 0.25},
+		{"pair 12", stats.TwoDigitFreq.FirstSecond[12], 0.75},
+		{"pair 34 (third, fourth)", stats.TwoDigitFreq.ThirdFourth[34], 0.75},
+		{"triple 234 (second to fourth)", stats.ThreeDigitFreq.SecondThirdFourth[234], 0.75},
+		{"number 1234", stats.FourDigitFreq.Complete[1234], 0.5},
+		{"number 1234 repeats every 3 draws", stats.Repetition.FourDigitRep[1234], 3},
+		{"first digit 1 repeats every 1.5 draws", stats.Repetition.DigitRep[0][1], 1.5},
+		{"pair 34 repeats every 1.5 draws", stats.Repetition.TwoDigitRep[5][34], 1.5},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !stats.DateRange.StartDate.Equal(draws[0].date) || !stats.DateRange.EndDate.Equal(draws[3].date) {
+		t.Errorf("date range = %+v", stats.DateRange)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lottery-analyzer/internal/model"
+	"lottery-analyzer/pkg/utils"
+)
+
+// Nombres de las estrategias disponibles
+const (
+	StrategyFrequency = "frequency"
+	StrategySingle    = "single"
+	StrategyPair      = "pair"
+	StrategyTriple    = "triple"
+	StrategyFull      = "full"
+	StrategyEnsemble  = "ensemble"
+	StrategyWeekday   = "weekday"
+)
+
+// Strategy puntúa el universo completo de números (0000-9999). El score más bajo es el mejor,
+// igual que en el ranking original.
+type Strategy interface {
+	Name() string
+	Scores(ctx context.Context, in *StrategyInput) ([]float64, error)
+}
+
+// modeScorer es una estrategia que ajusta sus scores a cada modo de selección, como el ensemble, que
+// ajusta pesos distintos para cold, hot y distance.
+type modeScorer interface {
+	modeScores(mode string) []float64
+}
+
+// scoresFor devuelve los scores con los que se ordena el modo: los de Scores salvo que la estrategia
+// tenga unos propios para el modo.
+func scoresFor(strategy Strategy, scores []float64, mode string) []float64 {
+	if s, ok := strategy.(modeScorer); ok {
+		return s.modeScores(mode)
+	}
+	return scores
+}
+
+// StrategyInput reúne los datos con los que puntúa una estrategia.
+type StrategyInput struct {
+	Frequencies *model.FrequencyData
+	History     []*model.Result // sorteos disponibles hasta AsOf, ordenados por fecha
+	AsOf        time.Time
+}
+
+// NewStrategy construye la estrategia por nombre con los parámetros del análisis.
+func NewStrategy(params model.AnalysisParams) (Strategy, error) {
+	switch params.Strategy {
+	case StrategyFrequency, "":
+		return componentStrategy{name: StrategyFrequency, score: calculateProbabilityResult}, nil
+	case StrategySingle:
+		return componentStrategy{name: StrategySingle, score: singleDigitScore}, nil
+	case StrategyPair:
+		return componentStrategy{name: StrategyPair, score: twoDigitScore}, nil
+	case StrategyTriple:
+		return componentStrategy{name: StrategyTriple, score: threeDigitScore}, nil
+	case StrategyFull:
+		return componentStrategy{name: StrategyFull, score: fourDigitScore}, nil
+	case StrategyEnsemble:
+		return newEnsembleStrategy(params)
+	case StrategyWeekday:
+		return weekdayStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy: %s", params.Strategy)
+}
+
+// componentStrategy puntúa cada número con una función sobre las frecuencias.
+type componentStrategy struct {
+	name  string
+	score func(number int, frecuencies *model.FrequencyData) float64
+}
+
+func (s componentStrategy) Name() string { return s.name }
+
+func (s componentStrategy) Scores(ctx context.Context, in *StrategyInput) ([]float64, error) {
+	return scoreAll(s.score, in.Frequencies), nil
+}
+
+func singleDigitScore(number int, frecuencies *model.FrequencyData) float64 {
+	d := utils.Digits(number)
+	return frecuencies.DigitFreq.Position1[d[0]] +
+		frecuencies.DigitFreq.Position2[d[1]] +
+		frecuencies.DigitFreq.Position3[d[2]] +
+		frecuencies.DigitFreq.Position4[d[3]]
+}
+
+func twoDigitScore(number int, frecuencies *model.FrequencyData) float64 {
+	d := utils.Digits(number)
+	return frecuencies.TwoDigitFreq.FirstSecond[d[0]*10+d[1]] +
+		frecuencies.TwoDigitFreq.SecondThird[d[1]*10+d[2]] +
+		frecuencies.TwoDigitFreq.ThirdFourth[d[2]*10+d[3]] +
+		frecuencies.TwoDigitFreq.FirstThird[d[0]*10+d[2]] +
+		frecuencies.TwoDigitFreq.FirstFourth[d[0]*10+d[3]] +
+		frecuencies.TwoDigitFreq.SecondFourth[d[1]*10+d[3]]
+}
+
+func threeDigitScore(number int, frecuencies *model.FrequencyData) float64 {
+	d := utils.Digits(number)
+	return frecuencies.ThreeDigitFreq.FirstSecondThird[d[0]*100+d[1]*10+d[2]] +
+		frecuencies.ThreeDigitFreq.FirstSecondFourth[d[0]*100+d[1]*10+d[3]] +
+		frecuencies.ThreeDigitFreq.FirstThirdFourth[d[0]*100+d[2]*10+d[3]] +
+		frecuencies.ThreeDigitFreq.SecondThirdFourth[d[1]*100+d[2]*10+d[3]]
+}
+
+func fourDigitScore(number int, frecuencies *model.FrequencyData) float64 {
+	return frecuencies.FourDigitFreq.Complete[number]
+}
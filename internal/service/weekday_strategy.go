@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// scheduleDraws son los últimos sorteos con los que se deduce en qué días de la semana juega la lotería.
+const scheduleDraws = 365
+
+// weekdayStrategy puntúa como frequency pero sólo con los sorteos que cayeron el mismo día de la
+// semana que el próximo sorteo.
+type weekdayStrategy struct{}
+
+func (weekdayStrategy) Name() string { return StrategyWeekday }
+
+func (weekdayStrategy) Scores(ctx context.Context, in *StrategyInput) ([]float64, error) {
+	draws, err := newDraws(in.History)
+	if err != nil {
+		return nil, err
+	}
+	if len(draws) == 0 {
+		return nil, fmt.Errorf("weekday strategy needs the draw history")
+	}
+
+	weekday := nextDrawWeekday(draws)
+
+	sameDay := make([]draw, 0, len(draws)/7+1)
+	for _, dr := range draws {
+		if dr.date.Weekday() == weekday {
+			sameDay = append(sameDay, dr)
+		}
+	}
+
+	frequencies, _ := frequenciesFromDraws(sameDay, day(in.AsOf))
+	return scoreAll(calculateProbabilityResult, frequencies), nil
+}
+
+// nextDrawWeekday es el primer día de juego posterior al último sorteo, según los días de la semana
+// en que hubo sorteos recientemente.
+func nextDrawWeekday(draws []draw) time.Weekday {
+	recent := draws
+	if len(recent) > scheduleDraws {
+		recent = recent[len(recent)-scheduleDraws:]
+	}
+
+	var playing [7]bool
+	for _, dr := range recent {
+		playing[dr.date.Weekday()] = true
+	}
+
+	last := draws[len(draws)-1].date.Weekday()
+	for offset := 1; offset <= 7; offset++ {
+		weekday := (last + time.Weekday(offset)) % 7
+		if playing[weekday] {
+			return weekday
+		}
+	}
+	return last
+}
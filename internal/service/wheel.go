@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+
+	"lottery-analyzer/internal/model"
+)
+
+const (
+	defaultWheelBudget = 10
+	// maxWheelBudget limita el costo: cada intercambio prueba todos los candidatos contra cada elegido
+	maxWheelBudget = 200
+	maxWheelSwaps  = 1000
+)
+
+// wheel busca Budget números que maximicen la probabilidad modelada de cubrir las terminaciones de
+// tres y dos cifras del próximo sorteo: primero de forma voraz y luego con intercambios de a uno
+// mientras mejoren el objetivo.
+type wheel struct {
+	params        model.WheelParams
+	probabilities []float64
+	lastThree     [1000]float64 // probabilidad de cada terminación de tres cifras
+	lastTwo       [100]float64
+	candidates    []int
+
+	chosen     []int
+	inSet      [10000]bool
+	threeCount [1000]int
+	twoCount   [100]int
+}
+
+func newWheel(params model.WheelParams, probabilities []float64, accept func(number int) bool) *wheel {
+	w := &wheel{params: params, probabilities: probabilities}
+	for number, probability := range probabilities {
+		w.lastThree[number%1000] += probability
+		w.lastTwo[number%100] += probability
+		if accept(number) {
+			w.candidates = append(w.candidates, number)
+		}
+	}
+	return w
+}
+
+func (w *wheel) add(number int) {
+	w.chosen = append(w.chosen, number)
+	w.inSet[number] = true
+	w.threeCount[number%1000]++
+	w.twoCount[number%100]++
+}
+
+func (w *wheel) replace(idx, number int) {
+	old := w.chosen[idx]
+	w.inSet[old] = false
+	w.threeCount[old%1000]--
+	w.twoCount[old%100]--
+
+	w.chosen[idx] = number
+	w.inSet[number] = true
+	w.threeCount[number%1000]++
+	w.twoCount[number%100]++
+}
+
+// gain es lo que suma al objetivo agregar number al conjunto actual.
+func (w *wheel) gain(number int) float64 {
+	gain := 0.0
+	if w.threeCount[number%1000] == 0 {
+		gain += w.params.ThreeWeight * w.lastThree[number%1000]
+	}
+	if w.twoCount[number%100] == 0 {
+		gain += w.params.TwoWeight * w.lastTwo[number%100]
+	}
+	return gain
+}
+
+// loss es lo que resta al objetivo quitar number del conjunto actual.
+func (w *wheel) loss(number int) float64 {
+	loss := 0.0
+	if w.threeCount[number%1000] == 1 {
+		loss += w.params.ThreeWeight * w.lastThree[number%1000]
+	}
+	if w.twoCount[number%100] == 1 {
+		loss += w.params.TwoWeight * w.lastTwo[number%100]
+	}
+	return loss
+}
+
+// better desempata por la probabilidad del número exacto y luego por el número menor.
+func (w *wheel) better(gain float64, number int, bestGain float64, best int) bool {
+	if best < 0 || gain > bestGain+1e-15 {
+		return true
+	}
+	if gain < bestGain-1e-15 {
+		return false
+	}
+	if w.probabilities[number] != w.probabilities[best] {
+		return w.probabilities[number] > w.probabilities[best]
+	}
+	return number < best
+}
+
+func (w *wheel) greedy() {
+	for len(w.chosen) < w.params.Budget && len(w.chosen) < len(w.candidates) {
+		best, bestGain := -1, 0.0
+		for _, number := range w.candidates {
+			if w.inSet[number] {
+				continue
+			}
+			if gain := w.gain(number); w.better(gain, number, bestGain, best) {
+				best, bestGain = number, gain
+			}
+		}
+		w.add(best)
+	}
+}
+
+// localSearch aplica el mejor intercambio (sale uno elegido, entra un candidato) mientras mejore el
+// objetivo; devuelve cuántos intercambios hizo. Se corta entre intercambios si se cancela ctx.
+func (w *wheel) localSearch(ctx context.Context) (int, error) {
+	swaps := 0
+	for swaps < maxWheelSwaps {
+		if err := ctx.Err(); err != nil {
+			return swaps, err
+		}
+
+		bestIdx, bestNumber, bestDelta := -1, -1, 1e-12
+		for idx, old := range w.chosen {
+			loss := w.loss(old)
+
+			// Se quita old un momento para medir la ganancia de cada candidato sin él
+			w.threeCount[old%1000]--
+			w.twoCount[old%100]--
+			for _, number := range w.candidates {
+				if w.inSet[number] {
+					continue
+				}
+				if delta := w.gain(number) - loss; delta > bestDelta {
+					bestIdx, bestNumber, bestDelta = idx, number, delta
+				}
+			}
+			w.threeCount[old%1000]++
+			w.twoCount[old%100]++
+		}
+
+		if bestIdx < 0 {
+			return swaps, nil
+		}
+		w.replace(bestIdx, bestNumber)
+		swaps++
+	}
+	return swaps, nil
+}
+
+// coverage mide un conjunto de números con la probabilidad modelada.
+func (w *wheel) coverage(numbers []int) model.WheelCoverage {
+	var three [1000]bool
+	var two [100]bool
+	var coverage model.WheelCoverage
+
+	for _, number := range numbers {
+		coverage.Exact += w.probabilities[number]
+		if !three[number%1000] {
+			three[number%1000] = true
+			coverage.LastThree += w.lastThree[number%1000]
+			coverage.DistinctLastThree++
+		}
+		if !two[number%100] {
+			two[number%100] = true
+			coverage.LastTwo += w.lastTwo[number%100]
+			coverage.DistinctLastTwo++
+		}
+	}
+
+	coverage.Objective = w.params.ThreeWeight*coverage.LastThree + w.params.TwoWeight*coverage.LastTwo
+	return coverage
+}
+
+// optimizeWheel elige los números y los compara con top, los primeros Budget del ranking del modo.
+func optimizeWheel(ctx context.Context, params model.WheelParams, scores []float64, mode string, filter *numberFilter, top []int) (*model.WheelResult, error) {
+	probabilities := modeledProbabilities(scores, mode, params.Temperature)
+	w := newWheel(params, probabilities, filter.accepts)
+
+	w.greedy()
+	swaps, err := w.localSearch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.WheelResult{
+		Mode:          mode,
+		Params:        params,
+		Numbers:       w.chosen,
+		Probabilities: make([]float64, len(w.chosen)),
+		Coverage:      w.coverage(w.chosen),
+		TopCoverage:   w.coverage(top),
+		Swaps:         swaps,
+	}
+	for i, number := range w.chosen {
+		result.Probabilities[i] = probabilities[number]
+	}
+	return result, nil
+}
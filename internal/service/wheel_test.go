@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"lottery-analyzer/internal/model"
+)
+
+func testFilter(t *testing.T, params model.AnalysisParams) *numberFilter {
+	t.Helper()
+	filter, err := newNumberFilter(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filter
+}
+
+func TestWheelCoverage(t *testing.T) {
+	probabilities := make([]float64, 10000)
+	for i := range probabilities {
+		probabilities[i] = 1.0 / 10000
+	}
+	w := newWheel(model.WheelParams{ThreeWeight: 1, TwoWeight: 1}, probabilities, acceptAll)
+
+	tests := []struct {
+		name          string
+		numbers       []int
+		distinctThree int
+		distinctTwo   int
+	}{
+		{"empty", nil, 0, 0},
+		{"same ending", []int{1234, 5234, 9234}, 1, 1},
+		{"same last two", []int{1234, 1334}, 2, 1},
+		{"all different", []int{1234, 5678, 9012}, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := w.coverage(tt.numbers)
+			if c.DistinctLastThree != tt.distinctThree || c.DistinctLastTwo != tt.distinctTwo {
+				t.Errorf("distinct endings = %d/%d, want %d/%d", c.DistinctLastThree, c.DistinctLastTwo, tt.distinctThree, tt.distinctTwo)
+			}
+			if want := float64(tt.distinctThree) / 1000; math.Abs(c.LastThree-want) > 1e-12 {
+				t.Errorf("last three coverage = %v, want %v", c.LastThree, want)
+			}
+			if want := float64(tt.distinctTwo) / 100; math.Abs(c.LastTwo-want) > 1e-12 {
+				t.Errorf("last two coverage = %v, want %v", c.LastTwo, want)
+			}
+			if want := c.LastThree + c.LastTwo; math.Abs(c.Objective-want) > 1e-12 {
+				t.Errorf("objective = %v, want %v", c.Objective, want)
+			}
+		})
+	}
+}
+
+func TestWheelCoversDistinctEndings(t *testing.T) {
+	// Con probabilidades uniformes cada número nuevo tiene que cubrir terminaciones nuevas
+	scores := make([]float64, 10000)
+	for _, budget := range []int{1, 10, 100} {
+		params := model.WheelParams{Budget: budget, Temperature: defaultTemperature, ThreeWeight: 1, TwoWeight: 1}
+		result, err := optimizeWheel(context.Background(), params, scores, ModeCold, testFilter(t, model.AnalysisParams{}), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Numbers) != budget {
+			t.Fatalf("budget %d: %d numbers", budget, len(result.Numbers))
+		}
+		if result.Coverage.DistinctLastTwo != budget || result.Coverage.DistinctLastThree != budget {
+			t.Errorf("budget %d: distinct endings %d/%d", budget, result.Coverage.DistinctLastThree, result.Coverage.DistinctLastTwo)
+		}
+	}
+}
+
+func TestWheelIsNotWorseThanTheRanking(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	scores := make([]float64, 10000)
+	for i := range scores {
+		scores[i] = rng.NormFloat64()
+	}
+	filter := testFilter(t, model.AnalysisParams{})
+
+	for _, mode := range SelectionModes {
+		for _, budget := range []int{5, 30} {
+			params := model.WheelParams{Budget: budget, Temperature: defaultTemperature, ThreeWeight: 1, TwoWeight: 1}
+			top := topNumbers(modeKeys(scores, mode), filter.accepts, budget)
+			result, err := optimizeWheel(context.Background(), params, scores, mode, filter, top)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Coverage.Objective < result.TopCoverage.Objective-1e-12 {
+				t.Errorf("%s budget %d: wheel objective %v is below the ranking's %v",
+					mode, budget, result.Coverage.Objective, result.TopCoverage.Objective)
+			}
+			seen := make(map[int]bool)
+			for _, number := range result.Numbers {
+				if seen[number] {
+					t.Errorf("%s budget %d: %04d chosen twice", mode, budget, number)
+				}
+				seen[number] = true
+			}
+		}
+	}
+}
+
+func TestWheelRespectsTheFilter(t *testing.T) {
+	scores := make([]float64, 10000)
+	filter := testFilter(t, model.AnalysisParams{Patterns: "palindrome"})
+	params := model.WheelParams{Budget: 20, Temperature: defaultTemperature, ThreeWeight: 1, TwoWeight: 1}
+
+	result, err := optimizeWheel(context.Background(), params, scores, ModeCold, filter, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, number := range result.Numbers {
+		if !filter.accepts(number) {
+			t.Errorf("%04d does not pass the filter", number)
+		}
+	}
+}
+
+func TestWheelStopsWhenCanceled(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	scores := make([]float64, 10000)
+	for i := range scores {
+		scores[i] = rng.NormFloat64()
+	}
+	params := model.WheelParams{Budget: 30, Temperature: defaultTemperature, ThreeWeight: 1, TwoWeight: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := optimizeWheel(ctx, params, scores, ModeHot, testFilter(t, model.AnalysisParams{}), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled wheel returned %v", err)
+	}
+}
+
+func TestWheelBudgetLimits(t *testing.T) {
+	p := newTestProcessor(newFakeResultRepo(1, testHistory(60, 13)...))
+	params := model.AnalysisParams{AsOf: "2020-02-20"}
+
+	tests := []struct {
+		budget int
+		want   int
+	}{
+		{0, defaultWheelBudget},
+		{1000000, maxWheelBudget},
+	}
+	for _, tt := range tests {
+		result, err := p.Wheel(context.Background(), params, model.WheelParams{Budget: tt.budget})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Params.Budget != tt.want || len(result.Numbers) != tt.want {
+			t.Errorf("budget %d gave %d numbers with budget %d, want %d", tt.budget, len(result.Numbers), result.Params.Budget, tt.want)
+		}
+	}
+}
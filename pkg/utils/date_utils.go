@@ -1 +1,11 @@
 package utils
+
+import "time"
+
+// DateLayout es el formato dd/mm/yyyy con el que la web publica las fechas de los sorteos.
+const DateLayout = "02/01/2006"
+
+// ParseDate convierte una fecha dd/mm/yyyy a time.Time.
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse(DateLayout, date)
+}
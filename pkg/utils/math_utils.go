@@ -1 +1,251 @@
 package utils
+
+import "math"
+
+// MeanStd devuelve la media y la desviación estándar poblacional de los valores.
+func MeanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// ChiSquarePValue devuelve P(X >= stat) para una chi-cuadrado con df grados de libertad.
+func ChiSquarePValue(stat float64, df int) float64 {
+	if df <= 0 || math.IsNaN(stat) {
+		return math.NaN()
+	}
+	if stat <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(df)/2, stat/2)
+}
+
+// upperIncompleteGamma es la función gamma incompleta superior regularizada Q(a, x), por serie
+// cuando x < a+1 y por fracción continua en otro caso.
+func upperIncompleteGamma(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*math.Exp(-x+a*math.Log(x)-lgamma)
+	}
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
+
+// ChiSquareGoodnessOfFit compara conteos observados con proporciones esperadas; las casillas con
+// esperado cero se ignoran. Devuelve el estadístico y los grados de libertad.
+func ChiSquareGoodnessOfFit(observed []int, expectedShare []float64) (float64, int) {
+	total := 0
+	for _, o := range observed {
+		total += o
+	}
+
+	stat, cells := 0.0, 0
+	for i, o := range observed {
+		expected := expectedShare[i] * float64(total)
+		if expected <= 0 {
+			continue
+		}
+		stat += (float64(o) - expected) * (float64(o) - expected) / expected
+		cells++
+	}
+
+	if cells < 2 {
+		return 0, 0
+	}
+	return stat, cells - 1
+}
+
+// ChiSquareIndependence es la prueba de independencia sobre una tabla de contingencia; las filas y
+// columnas vacías se ignoran. Devuelve el estadístico y los grados de libertad.
+func ChiSquareIndependence(table [][]int) (float64, int) {
+	if len(table) == 0 {
+		return 0, 0
+	}
+
+	rowTotals := make([]int, len(table))
+	colTotals := make([]int, len(table[0]))
+	total := 0
+	for i, row := range table {
+		for j, count := range row {
+			rowTotals[i] += count
+			colTotals[j] += count
+			total += count
+		}
+	}
+
+	rows, cols := 0, 0
+	for _, t := range rowTotals {
+		if t > 0 {
+			rows++
+		}
+	}
+	for _, t := range colTotals {
+		if t > 0 {
+			cols++
+		}
+	}
+	if rows < 2 || cols < 2 {
+		return 0, 0
+	}
+
+	stat := 0.0
+	for i, row := range table {
+		for j, count := range row {
+			expected := float64(rowTotals[i]) * float64(colTotals[j]) / float64(total)
+			if expected <= 0 {
+				continue
+			}
+			stat += (float64(count) - expected) * (float64(count) - expected) / expected
+		}
+	}
+
+	return stat, (rows - 1) * (cols - 1)
+}
+
+// Pearson devuelve el coeficiente de correlación lineal entre x e y (0 si alguno es constante).
+func Pearson(x, y []float64) float64 {
+	meanX, stdX := MeanStd(x)
+	meanY, stdY := MeanStd(y)
+	if len(x) == 0 || stdX == 0 || stdY == 0 {
+		return 0
+	}
+
+	cov := 0.0
+	for i := range x {
+		cov += (x[i] - meanX) * (y[i] - meanY)
+	}
+	return cov / float64(len(x)) / (stdX * stdY)
+}
+
+// PearsonPValue es el p-valor bilateral de r con n pares, con la transformación z de Fisher.
+func PearsonPValue(r float64, n int) float64 {
+	if n <= 3 {
+		return 1
+	}
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+	z := math.Atanh(r) * math.Sqrt(float64(n-3))
+	return NormalTwoSidedPValue(z)
+}
+
+// NormalTwoSidedPValue devuelve P(|Z| >= |z|) para una normal estándar.
+func NormalTwoSidedPValue(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// BinomialPValue es el p-valor exacto bilateral de observar k éxitos en n ensayos con probabilidad
+// p: suma las probabilidades de los resultados no más probables que k.
+func BinomialPValue(k, n int, p float64) float64 {
+	if n <= 0 || p <= 0 || p >= 1 {
+		return 1
+	}
+
+	logPMF := func(i int) float64 {
+		lgN, _ := math.Lgamma(float64(n + 1))
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgR, _ := math.Lgamma(float64(n - i + 1))
+		return lgN - lgI - lgR + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p)
+	}
+
+	limit := logPMF(k) + 1e-7
+	pValue := 0.0
+	for i := 0; i <= n; i++ {
+		if lp := logPMF(i); lp <= limit {
+			pValue += math.Exp(lp)
+		}
+	}
+	return math.Min(1, pValue)
+}
+
+// MutualInformation devuelve la información mutua, en bits, entre las filas y columnas de una tabla
+// de contingencia.
+func MutualInformation(table [][]int) float64 {
+	if len(table) == 0 {
+		return 0
+	}
+
+	rowTotals := make([]int, len(table))
+	colTotals := make([]int, len(table[0]))
+	total := 0
+	for i, row := range table {
+		for j, count := range row {
+			rowTotals[i] += count
+			colTotals[j] += count
+			total += count
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	n := float64(total)
+	mi := 0.0
+	for i, row := range table {
+		for j, count := range row {
+			if count == 0 {
+				continue
+			}
+			pxy := float64(count) / n
+			mi += pxy * math.Log2(pxy*n*n/(float64(rowTotals[i])*float64(colTotals[j])))
+		}
+	}
+	return mi
+}
+
+// CramersV normaliza el chi-cuadrado de una tabla rows x cols con n observaciones a [0, 1].
+func CramersV(stat float64, n, rows, cols int) float64 {
+	k := rows
+	if cols < k {
+		k = cols
+	}
+	if n == 0 || k < 2 {
+		return 0
+	}
+	return math.Sqrt(stat / (float64(n) * float64(k-1)))
+}
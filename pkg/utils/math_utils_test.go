@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		stat  float64
+		df    int
+		want  float64
+		delta float64
+	}{
+		{"df 1 critical value", 3.841, 1, 0.05, 1e-4},
+		{"df 2 critical value", 5.991, 2, 0.05, 1e-4},
+		{"df 10 critical value", 18.307, 10, 0.05, 1e-4},
+		{"df 1 at 0.01", 6.635, 1, 0.01, 1e-4},
+		// Con df = 2 la cola es exactamente exp(-stat/2), por serie y por fracción continua
+		{"df 2 series", 1, 2, math.Exp(-0.5), 1e-12},
+		{"df 2 continued fraction", 10, 2, math.Exp(-5), 1e-12},
+		// Con df = 4 es exp(-x)(1+x) con x = stat/2
+		{"df 4", 1, 4, math.Exp(-0.5) * 1.5, 1e-12},
+		{"zero statistic", 0, 3, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChiSquarePValue(tt.stat, tt.df); !near(got, tt.want, tt.delta) {
+				t.Errorf("ChiSquarePValue(%v, %d) = %v, want %v", tt.stat, tt.df, got, tt.want)
+			}
+		})
+	}
+
+	if !math.IsNaN(ChiSquarePValue(1, 0)) {
+		t.Error("ChiSquarePValue with no degrees of freedom is not NaN")
+	}
+}
+
+func TestChiSquareGoodnessOfFit(t *testing.T) {
+	tests := []struct {
+		name     string
+		observed []int
+		expected []float64
+		stat     float64
+		df       int
+	}{
+		{"perfect fit", []int{25, 25, 50}, []float64{0.25, 0.25, 0.5}, 0, 2},
+		{"coin", []int{60, 40}, []float64{0.5, 0.5}, 4, 1},
+		{"zero expected cell ignored", []int{60, 40, 0}, []float64{0.5, 0.5, 0}, 4, 1},
+		{"single cell", []int{10, 0}, []float64{1, 0}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stat, df := ChiSquareGoodnessOfFit(tt.observed, tt.expected)
+			if !near(stat, tt.stat, 1e-12) || df != tt.df {
+				t.Errorf("got %v with %d df, want %v with %d df", stat, df, tt.stat, tt.df)
+			}
+		})
+	}
+}
+
+func TestChiSquareIndependence(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]int
+		stat  float64
+		df    int
+	}{
+		{"independent", [][]int{{10, 10}, {10, 10}}, 0, 1},
+		{"fully dependent", [][]int{{10, 0}, {0, 10}}, 20, 1},
+		{"empty row and column ignored", [][]int{{10, 0, 0}, {0, 10, 0}, {0, 0, 0}}, 20, 1},
+		{"single row", [][]int{{5, 5}}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stat, df := ChiSquareIndependence(tt.table)
+			if !near(stat, tt.stat, 1e-12) || df != tt.df {
+				t.Errorf("got %v with %d df, want %v with %d df", stat, df, tt.stat, tt.df)
+			}
+		})
+	}
+}
+
+func TestPearsonPValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     float64
+		n     int
+		want  float64
+		delta float64
+	}{
+		{"no correlation", 0, 100, 1, 1e-12},
+		{"too few pairs", 0.9, 3, 1, 0},
+		{"perfect correlation", -1, 50, 0, 0},
+		// z de Fisher = 1.96 con n = 28 es la frontera del 5 %
+		{"critical value", math.Tanh(1.96 / 5), 28, 0.05, 1e-3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PearsonPValue(tt.r, tt.n); !near(got, tt.want, tt.delta) {
+				t.Errorf("PearsonPValue(%v, %d) = %v, want %v", tt.r, tt.n, got, tt.want)
+			}
+		})
+	}
+
+	if got := Pearson([]float64{1, 2, 3}, []float64{2, 4, 6}); !near(got, 1, 1e-12) {
+		t.Errorf("Pearson of a line = %v, want 1", got)
+	}
+}
+
+func TestBinomialPValue(t *testing.T) {
+	tests := []struct {
+		name string
+		k, n int
+		p    float64
+		want float64
+	}{
+		{"most likely", 5, 10, 0.5, 1},
+		{"none", 0, 10, 0.5, 2.0 / 1024},
+		{"all", 10, 10, 0.5, 2.0 / 1024},
+		{"eight of ten", 8, 10, 0.5, 112.0 / 1024},
+		// Con p = 0.1 y n = 2: P(2) = 0.01 y es el único resultado tan improbable
+		{"skewed", 2, 2, 0.1, 0.01},
+		{"no trials", 0, 0, 0.5, 1},
+		{"degenerate p", 3, 10, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BinomialPValue(tt.k, tt.n, tt.p); !near(got, tt.want, 1e-9) {
+				t.Errorf("BinomialPValue(%d, %d, %v) = %v, want %v", tt.k, tt.n, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	identical := make([][]int, 10)
+	for i := range identical {
+		identical[i] = make([]int, 10)
+		identical[i][i] = 5
+	}
+
+	tests := []struct {
+		name  string
+		table [][]int
+		want  float64
+	}{
+		{"independent", [][]int{{5, 5}, {5, 5}}, 0},
+		{"independent, uneven marginals", [][]int{{6, 2}, {3, 1}}, 0},
+		{"identical binary columns", [][]int{{5, 0}, {0, 5}}, 1},
+		{"identical digit columns", identical, math.Log2(10)},
+		{"empty", [][]int{{0, 0}, {0, 0}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MutualInformation(tt.table); !near(got, tt.want, 1e-12) {
+				t.Errorf("MutualInformation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCramersV(t *testing.T) {
+	tests := []struct {
+		name             string
+		stat             float64
+		n, rows, columns int
+		want             float64
+	}{
+		{"independent", 0, 40, 2, 2, 0},
+		{"fully dependent 2x2", 20, 20, 2, 2, 1},
+		// En una tabla 10x10 el máximo es n * 9
+		{"fully dependent 10x10", 450, 50, 10, 10, 1},
+		{"half", 5, 20, 2, 2, 0.5},
+		{"single column", 3, 20, 5, 1, 0},
+		{"no observations", 3, 0, 2, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CramersV(tt.stat, tt.n, tt.rows, tt.columns); !near(got, tt.want, 1e-12) {
+				t.Errorf("CramersV = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
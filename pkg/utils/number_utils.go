@@ -1 +1,6 @@
 package utils
+
+// Digits separa un número de cuatro cifras en sus dígitos sin pasar por strings.
+func Digits(number int) [4]int {
+	return [4]int{number / 1000 % 10, number / 100 % 10, number / 10 % 10, number % 10}
+}